@@ -1,16 +1,21 @@
 package app
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"log"
 	"os"
 	"os/user"
 	"sort"
+	"strconv"
 	"strings"
 
 	"github.com/MichaelThessel/gomainr/file"
+	"github.com/MichaelThessel/gomainr/output"
 	"github.com/MichaelThessel/gomainr/search"
+	"github.com/MichaelThessel/gomainr/search/source"
 
 	"github.com/jroimartin/gocui"
 )
@@ -20,14 +25,21 @@ type App struct {
 	currentView int
 	s           *search.Search
 	state       *state
+	results     []search.Result
+	searchID    int
+	cancel      context.CancelFunc
 }
 
 type state struct {
-	Parts1   []string
-	Parts2   []string
-	Tlds     []string
-	Domains  []string
-	Settings map[string]bool
+	Parts           []search.Part
+	Parts1          []string `json:",omitempty"` // Deprecated: only read from old files
+	Parts2          []string `json:",omitempty"`
+	Tlds            []string
+	Domains         []string
+	Settings        map[string]bool
+	MaxPremiumPrice int64
+	MaxPrice        int64
+	Currency        string
 }
 
 func New(s *search.Search) *App {
@@ -45,7 +57,10 @@ func New(s *search.Search) *App {
 	a.state = new(state)
 	a.state.Settings = map[string]bool{
 		"TLDSubstitutions": false,
+		"HidePremium":      false,
+		"SortByPrice":      false,
 	}
+	a.state.Parts = make([]search.Part, 2)
 
 	a.initGui()
 
@@ -61,6 +76,7 @@ func (a *App) Loop() {
 
 // Close closes the app
 func (a *App) Close() {
+	a.stopSearch()
 	a.gui.Close()
 }
 
@@ -94,18 +110,22 @@ func (a *App) writeConsole(text string, isError bool) {
 // search builds the domain names from the parts and updates the result list with
 // the available ones
 func (a *App) search(g *gocui.Gui, v *gocui.View) error {
+	// A new search supersedes any search that is still running
+	a.stopSearch()
+
 	a.clearView(viewDomain)
+	a.state.Domains = []string{}
+	a.results = []search.Result{}
 
 	if !a.validate() {
 		return nil
 	}
 
-	a.writeConsole("Searching ...", false)
+	a.writeConsole("Searching ... (<CTL>x: cancel)", false)
 
 	// Generate domain list from parts
 	domains := a.s.BuildQuery(
-		a.state.Parts1,
-		a.state.Parts2,
+		a.state.Parts,
 		a.state.Tlds,
 		a.state.Settings["TLDSubstitutions"],
 	)
@@ -114,83 +134,199 @@ func (a *App) search(g *gocui.Gui, v *gocui.View) error {
 		a.writeConsole("No possible searches!", true)
 	}
 
-	jobs := make(chan string, len(domains))
-	found := make(chan string)
-	complete := make(chan bool)
+	ctx, cancel := context.WithCancel(context.Background())
+	a.searchID++
+	a.cancel = cancel
+	id := a.searchID
 
-	for _, domain := range domains {
-		jobs <- domain
-	}
-	close(jobs)
+	results := a.s.Run(ctx, domains)
 
-	// Create the workers that fetch available domain names
-	workerCount := 4
-	var apiErr error
-	for i := 0; i < workerCount; i++ {
-		go func(jobs <-chan string, found chan<- string, complete chan<- bool) {
-			for domain := range jobs {
-				available, err := a.s.IsAvailable(domain)
-				if err != nil {
-					apiErr = err
-					break
-				}
-				if available {
-					found <- domain
-				}
-			}
-			complete <- true
-		}(jobs, found, complete)
-	}
+	// Update the domain list as results come in. Updates are dropped once
+	// the search has been superseded by a new one.
+	go func() {
+		checked := 0
+		for result := range results {
+			checked++
 
-	// Update the domain list as results come in
-	foundDomains := []string{}
-	go func(found <-chan string) {
-		for domain := range found {
-			foundDomains = append(foundDomains, domain)
+			r := result
 			a.gui.Update(func(g *gocui.Gui) error {
-				sort.Strings(foundDomains)
-
-				a.writeView(viewDomain, decorate(strings.Join(foundDomains, "\n"), "blue"))
+				if id == a.searchID {
+					a.results = append(a.results, r)
+					a.showResults()
+				}
 				return nil
 			})
 		}
 
+		cancelled := ctx.Err() != nil
+		cancel()
+
 		a.gui.Update(func(g *gocui.Gui) error {
-			if apiErr != nil {
+			if id != a.searchID {
+				return nil
+			}
+			a.cancel = nil
+
+			available, failed := a.countResults()
+			if cancelled {
 				a.writeConsole(
-					fmt.Sprintf("API error: %s", apiErr),
+					fmt.Sprintf(
+						"Search cancelled: Scanned %d of %d domain(s) - %d domain(s) available - %s",
+						checked,
+						len(domains),
+						available,
+						a.errorSummary(failed),
+					),
 					true,
 				)
 			} else {
 				a.writeConsole(
 					fmt.Sprintf(
-						"Search complete: Scanned %d domain(s) - %d domain(s) available",
+						"Search complete: Scanned %d domain(s) - %d domain(s) available - %s",
 						len(domains),
-						len(foundDomains),
+						available,
+						a.errorSummary(failed),
 					),
-					false,
+					failed > 0,
 				)
 			}
 			return nil
 		})
-	}(found)
-
-	// Signal completion to the domain list update goroutine
-	go func(found chan string, complete chan bool) {
-		i := 0
-		for range complete {
-			i++
-			if i == workerCount {
-				close(found)
-				close(complete)
-			}
+	}()
 
-		}
-	}(found, complete)
+	return nil
+}
+
+// cancelSearch cancels the running search
+func (a *App) cancelSearch(g *gocui.Gui, v *gocui.View) error {
+	if a.cancel == nil {
+		a.writeConsole("No search running", false)
+		return nil
+	}
+
+	a.writeConsole("Cancelling search ...", false)
+	a.stopSearch()
 
 	return nil
 }
 
+// stopSearch stops the running search if there is one
+func (a *App) stopSearch() {
+	if a.cancel != nil {
+		a.cancel()
+	}
+}
+
+// showResults updates the result list with the available, disputed and
+// failed domains of the current search
+func (a *App) showResults() {
+	var listed []search.Result
+	for _, r := range a.results {
+		if r.Err != nil || r.Agreement == source.Disagreed || a.isAvailable(r) {
+			listed = append(listed, r)
+		}
+	}
+
+	if a.state.Settings["SortByPrice"] {
+		search.SortByPrice(listed)
+	} else {
+		sort.Slice(listed, func(i, j int) bool {
+			return listed[i].Domain < listed[j].Domain
+		})
+	}
+
+	// Align the price column
+	width := 0
+	for _, r := range listed {
+		if len(r.Domain) > width {
+			width = len(r.Domain)
+		}
+	}
+
+	a.state.Domains = make([]string, 0, len(listed))
+	lines := make([]string, 0, len(listed))
+	for _, r := range listed {
+		switch {
+		case r.Err != nil:
+			lines = append(lines, decorate(formatResult(r, width), "red"))
+			continue
+		case r.Agreement == source.Disagreed:
+			lines = append(lines, decorate(formatResult(r, width), "yellow"))
+		default:
+			lines = append(lines, decorate(formatResult(r, width), "blue"))
+		}
+
+		if a.isAvailable(r) {
+			a.state.Domains = append(a.state.Domains, r.Domain)
+		}
+	}
+
+	a.writeView(viewDomain, strings.Join(lines, "\n"))
+}
+
+// countResults returns the number of available and failed domains of the
+// current search
+func (a *App) countResults() (available int, failed int) {
+	for _, r := range a.results {
+		switch {
+		case r.Err != nil:
+			failed++
+		case a.isAvailable(r):
+			available++
+		}
+	}
+
+	return
+}
+
+// errorSummary describes the failed domains of the current search broken down
+// by error kind
+func (a *App) errorSummary(failed int) string {
+	if failed == 0 {
+		return "0 error(s)"
+	}
+
+	counts := make(map[error]int)
+	for _, r := range a.results {
+		if r.Err != nil {
+			counts[source.KindOf(r.Err)]++
+		}
+	}
+
+	var kinds []string
+	for _, kind := range source.ErrorKinds {
+		if counts[kind] > 0 {
+			kinds = append(kinds, fmt.Sprintf("%s: %d", kind, counts[kind]))
+		}
+	}
+	if counts[nil] > 0 {
+		kinds = append(kinds, fmt.Sprintf("Other: %d", counts[nil]))
+	}
+
+	summary := fmt.Sprintf("%d error(s) (%s)", failed, strings.Join(kinds, ", "))
+	if counts[source.ErrAuth] > 0 {
+		summary += " - check the API credentials in the config file"
+	}
+
+	return summary
+}
+
+// isAvailable returns true if a result is available and not hidden by the
+// filter
+func (a *App) isAvailable(r search.Result) bool {
+	return r.Available() && !a.filter().Excludes(r)
+}
+
+// filter returns the result filter for the current settings
+func (a *App) filter() search.Filter {
+	return search.Filter{
+		HidePremium:     a.state.Settings["HidePremium"],
+		MaxPremiumPrice: a.state.MaxPremiumPrice,
+		MaxPrice:        a.state.MaxPrice,
+		Currency:        a.state.Currency,
+	}
+}
+
 // saveModal opens the save modal
 func (a *App) saveModal(g *gocui.Gui, v *gocui.View) error {
 	usr, err := user.Current()
@@ -229,6 +365,44 @@ func (a *App) loadModal(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+// maxPriceModal opens the max price modal
+func (a *App) maxPriceModal(g *gocui.Gui, v *gocui.View) error {
+	text := ""
+	if a.state.MaxPrice > 0 {
+		text = output.FormatPrice(a.state.MaxPrice)
+	}
+
+	a.showModal(viewMaxPrice, text, 0.2, 0.07)
+
+	a.writeConsole("Please enter the maximum price (empty to disable).", false)
+
+	return nil
+}
+
+// setMaxPrice sets the max price from the max price modal
+func (a *App) setMaxPrice(g *gocui.Gui, v *gocui.View) error {
+	input := strings.TrimSpace(v.Buffer())
+
+	var price float64
+	if input != "" {
+		var err error
+		price, err = strconv.ParseFloat(input, 64)
+		if err != nil || price < 0 {
+			a.writeConsole(fmt.Sprintf("Invalid price: %s", input), true)
+			return nil
+		}
+	}
+
+	a.state.MaxPrice = int64(price * 1000000)
+	a.showResults()
+
+	a.writeConsole("The max price has been updated.", false)
+
+	a.closeView(v.Name())
+
+	return nil
+}
+
 // close Closes a view
 func (a *App) closeModal(g *gocui.Gui, v *gocui.View) error {
 	a.closeView(v.Name())
@@ -244,10 +418,23 @@ func (a *App) save(g *gocui.Gui, v *gocui.View) error {
 	}
 
 	fd, _, err := file.CreateFile(saveFile)
-	if err != nil {
+	if err != nil || fd == nil {
 		a.writeConsole(fmt.Sprintf("Couldn't create file: %s", saveFile), false)
 		return nil
 	}
+	defer fd.Close()
+
+	// Export the results if the file extension matches an export format
+	if format := output.FormatFromFile(saveFile); format != "" {
+		if err := a.export(fd, format); err != nil {
+			a.writeConsole(fmt.Sprintf("Couldn't write to file: %s", saveFile), false)
+			return nil
+		}
+
+		a.writeConsole(fmt.Sprintf("The results have been exported to: %s", saveFile), false)
+		a.closeView(v.Name())
+		return nil
+	}
 
 	json, err := json.MarshalIndent(a.state, "", "    ")
 	if err != nil {
@@ -267,6 +454,22 @@ func (a *App) save(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+// export writes the results of the last search in the given format
+func (a *App) export(w io.Writer, format string) error {
+	ow, err := output.New(format, w)
+	if err != nil {
+		return err
+	}
+
+	for _, result := range a.results {
+		if err := ow.Write(result); err != nil {
+			return err
+		}
+	}
+
+	return ow.Flush()
+}
+
 // load loads state from a file
 func (a *App) load(g *gocui.Gui, v *gocui.View) error {
 	loadFile := strings.TrimSpace(v.Buffer())
@@ -276,13 +479,27 @@ func (a *App) load(g *gocui.Gui, v *gocui.View) error {
 		return nil
 	}
 
-	if err := json.Unmarshal(data, a.state); err != nil {
+	loaded := *a.state
+	loaded.Parts, loaded.Parts1, loaded.Parts2 = nil, nil, nil
+	if err := json.Unmarshal(data, &loaded); err != nil {
 		a.writeConsole(fmt.Sprintf("Couldn't parse file: %s", loadFile), false)
 		return nil
 	}
 
-	a.writeView(viewPart1, strings.Join(a.state.Parts1, " "))
-	a.writeView(viewPart2, strings.Join(a.state.Parts2, " "))
+	// Files saved by older versions only have two part lists
+	if len(loaded.Parts) == 0 {
+		loaded.Parts = []search.Part{{Words: loaded.Parts1}, {Words: loaded.Parts2}}
+	}
+	if len(loaded.Parts) > maxParts {
+		loaded.Parts = loaded.Parts[:maxParts]
+	}
+	loaded.Parts1, loaded.Parts2 = nil, nil
+	*a.state = loaded
+
+	if err := a.refreshParts(); err != nil {
+		return err
+	}
+
 	a.writeView(viewTLD, strings.Join(a.state.Tlds, " "))
 	a.writeView(viewDomain, decorate(strings.Join(a.state.Domains, "\n"), "blue"))
 
@@ -300,6 +517,110 @@ func (a *App) toggleTLDSubsitutions(g *gocui.Gui, v *gocui.View) error {
 	return nil
 }
 
+// toggleHidePremium toggles hiding of premium domains
+func (a *App) toggleHidePremium(g *gocui.Gui, v *gocui.View) error {
+	a.setSetting("HidePremium", !a.state.Settings["HidePremium"])
+	a.showResults()
+
+	return nil
+}
+
+// toggleSortByPrice toggles sorting of the result list by price
+func (a *App) toggleSortByPrice(g *gocui.Gui, v *gocui.View) error {
+	a.setSetting("SortByPrice", !a.state.Settings["SortByPrice"])
+	a.showResults()
+
+	return nil
+}
+
+// addPart adds an empty part after the focussed one
+func (a *App) addPart(g *gocui.Gui, v *gocui.View) error {
+	if len(a.state.Parts) >= maxParts {
+		a.writeConsole(fmt.Sprintf("No more than %d parts are supported", maxParts), true)
+		return nil
+	}
+
+	a.updateState()
+
+	index := a.currentView + 1
+	if index > len(a.state.Parts) {
+		index = len(a.state.Parts)
+	}
+	a.state.Parts = append(a.state.Parts, search.Part{})
+	copy(a.state.Parts[index+1:], a.state.Parts[index:])
+	a.state.Parts[index] = search.Part{}
+
+	if err := a.refreshParts(); err != nil {
+		return err
+	}
+
+	return a.setSelectableView(index)
+}
+
+// removePart removes the focussed part
+func (a *App) removePart(g *gocui.Gui, v *gocui.View) error {
+	index := a.partIndex(v.Name())
+	if index == -1 {
+		return nil
+	}
+	if len(a.state.Parts) == 1 {
+		a.writeConsole("The last part can't be removed", true)
+		return nil
+	}
+
+	a.updateState()
+	a.state.Parts = append(a.state.Parts[:index], a.state.Parts[index+1:]...)
+
+	if err := a.refreshParts(); err != nil {
+		return err
+	}
+
+	if index == len(a.state.Parts) {
+		index--
+	}
+
+	return a.setSelectableView(index)
+}
+
+// toggleOptionalPart toggles whether names are also built without the focussed
+// part
+func (a *App) toggleOptionalPart(g *gocui.Gui, v *gocui.View) error {
+	index := a.partIndex(v.Name())
+	if index == -1 {
+		return nil
+	}
+
+	a.state.Parts[index].Optional = !a.state.Parts[index].Optional
+
+	return nil
+}
+
+// refreshParts recreates the part rows from the current state
+func (a *App) refreshParts() error {
+	if err := a.layoutRows(); err != nil {
+		return err
+	}
+
+	for i, part := range a.state.Parts {
+		a.writeView(partView(i), strings.Join(part.Words, " "))
+	}
+
+	// Delete the rows of removed parts
+	for i := len(a.state.Parts); i < maxParts; i++ {
+		a.gui.DeleteView(partView(i))
+	}
+
+	return nil
+}
+
+// SetFilter sets the default result filter
+func (a *App) SetFilter(f search.Filter) {
+	a.setSetting("HidePremium", f.HidePremium)
+	a.state.MaxPremiumPrice = f.MaxPremiumPrice
+	a.state.MaxPrice = f.MaxPrice
+	a.state.Currency = f.Currency
+}
+
 // setSetting updates a setting
 func (a *App) setSetting(name string, value bool) {
 	a.state.Settings[name] = value
@@ -307,8 +628,14 @@ func (a *App) setSetting(name string, value bool) {
 
 // validate validates that the required fields are populated
 func (a *App) validate() bool {
-	if len(a.state.Parts1) == 0 {
-		a.writeConsole("\"Parts 1\" cannot be empty! Please enter space seperated list of domain parts.", true)
+	empty := true
+	for _, part := range a.state.Parts {
+		if len(part.Words) > 0 {
+			empty = false
+		}
+	}
+	if empty {
+		a.writeConsole("\"Parts\" cannot be empty! Please enter space seperated list of domain parts.", true)
 		return false
 	}
 
@@ -330,19 +657,43 @@ func (a *App) validate() bool {
 
 // updateState saves the current state of views
 func (a *App) updateState() {
-	a.state.Parts1 = a.parseLine(viewPart1)
-	a.state.Parts2 = a.parseLine(viewPart2)
+	for i := range a.state.Parts {
+		a.state.Parts[i].Words = a.parseLine(partView(i))
+	}
 	a.state.Tlds = a.parseLine(viewTLD)
-	a.state.Domains = a.parseLine(viewDomain)
 }
 
 // updateViews updates the views based on the current state
 func (a *App) updateViews() {
-	if a.state.Settings["TLDSubstitutions"] {
-		a.writeView(viewSettings, "[X] TLD substitutions")
+	settings := []struct {
+		name  string
+		title string
+	}{
+		{"TLDSubstitutions", "TLD substitutions"},
+		{"HidePremium", "Hide premium domains"},
+		{"SortByPrice", "Sort by price"},
+	}
+
+	var parts []string
+	for _, setting := range settings {
+		if a.state.Settings[setting.name] {
+			parts = append(parts, "[X] "+setting.title)
+		} else {
+			parts = append(parts, "[ ] "+setting.title)
+		}
+	}
+
+	if a.state.MaxPrice > 0 {
+		parts = append(parts, fmt.Sprintf("Max price: %s", output.FormatPrice(a.state.MaxPrice)))
 	} else {
-		a.writeView(viewSettings, "[ ] TLD substitutions")
+		parts = append(parts, "Max price: none")
+	}
+
+	if a.state.MaxPremiumPrice > 0 {
+		parts = append(parts, fmt.Sprintf("Max premium price: %s", output.FormatPrice(a.state.MaxPremiumPrice)))
 	}
+
+	a.writeView(viewSettings, strings.Join(parts, " | "))
 }
 
 // getViewWords returns the list of words in a view (space separated)
@@ -362,6 +713,33 @@ func (a *App) parseLine(view string) []string {
 	return unique
 }
 
+// formatResult formats a search result for the result list. The domain is
+// padded to width so prices line up.
+func formatResult(r search.Result, width int) string {
+	line := fmt.Sprintf("%-*s", width, r.Domain)
+
+	if r.Err != nil {
+		return line + fmt.Sprintf("  %s (error: %s)", r.Status, r.Err)
+	}
+
+	if r.Price > 0 {
+		line += fmt.Sprintf("  %10s %s", output.FormatPrice(r.Price), r.Currency)
+		if r.RenewalPrice > 0 {
+			line += fmt.Sprintf(" (renews at %s)", output.FormatPrice(r.RenewalPrice))
+		}
+	}
+
+	if r.Status != source.StatusAvailable {
+		line += fmt.Sprintf("  (%s)", r.Status)
+	}
+
+	if r.Agreement == source.Disagreed {
+		line += fmt.Sprintf("  (sources disagree: %s)", r.Message)
+	}
+
+	return line
+}
+
 // decorate changes the color of a string
 func decorate(s string, color string) string {
 	switch color {
@@ -369,6 +747,8 @@ func decorate(s string, color string) string {
 		s = "\x1b[0;32m" + s
 	case "red":
 		s = "\x1b[0;31m" + s
+	case "yellow":
+		s = "\x1b[0;33m" + s
 	default:
 		return s
 	}
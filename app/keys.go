@@ -15,65 +15,107 @@ type keyConfig struct {
 func (a *App) setKeyBindings() error {
 	var kc = []keyConfig{
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyCtrlQ,
 			gocui.ModNone,
 			a.quit,
 		},
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyTab,
 			gocui.ModNone,
 			a.wrapEditor,
 		},
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyArrowDown,
 			gocui.ModNone,
 			a.nextEditor,
 		},
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyArrowUp,
 			gocui.ModNone,
 			a.prevEditor,
 		},
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyCtrlSlash,
 			gocui.ModNone,
 			a.search,
 		},
 		{
-			&selectableViews,
+			&editorViews,
+			gocui.KeyCtrlX,
+			gocui.ModNone,
+			a.cancelSearch,
+		},
+		{
+			&editorViews,
 			gocui.KeyCtrlK,
 			gocui.ModNone,
 			a.scrollUp,
 		},
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyCtrlR,
 			gocui.ModNone,
 			a.toggleTLDSubsitutions,
 		},
 		{
-			&selectableViews,
+			&editorViews,
+			gocui.KeyCtrlP,
+			gocui.ModNone,
+			a.toggleHidePremium,
+		},
+		{
+			&editorViews,
+			gocui.KeyCtrlO,
+			gocui.ModNone,
+			a.toggleSortByPrice,
+		},
+		{
+			&editorViews,
+			gocui.KeyCtrlE,
+			gocui.ModNone,
+			a.maxPriceModal,
+		},
+		{
+			&editorViews,
 			gocui.KeyCtrlJ,
 			gocui.ModNone,
 			a.scrollDown,
 		},
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyCtrlS,
 			gocui.ModNone,
 			a.saveModal,
 		},
 		{
-			&selectableViews,
+			&editorViews,
 			gocui.KeyCtrlL,
 			gocui.ModNone,
 			a.loadModal,
 		},
+		{
+			&editorViews,
+			gocui.KeyCtrlN,
+			gocui.ModNone,
+			a.addPart,
+		},
+		{
+			&editorViews,
+			gocui.KeyCtrlD,
+			gocui.ModNone,
+			a.removePart,
+		},
+		{
+			&editorViews,
+			gocui.KeyCtrlT,
+			gocui.ModNone,
+			a.toggleOptionalPart,
+		},
 		{
 			&[]string{viewSave},
 			gocui.KeyEnter,
@@ -87,7 +129,13 @@ func (a *App) setKeyBindings() error {
 			a.load,
 		},
 		{
-			&[]string{viewSave, viewLoad},
+			&[]string{viewMaxPrice},
+			gocui.KeyEnter,
+			gocui.ModNone,
+			a.setMaxPrice,
+		},
+		{
+			&[]string{viewSave, viewLoad, viewMaxPrice},
 			gocui.KeyCtrlQ,
 			gocui.ModNone,
 			a.closeModal,
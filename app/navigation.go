@@ -29,6 +29,7 @@ func (a *App) wrapEditor(g *gocui.Gui, v *gocui.View) error {
 // switchEditor changes focus to next/previous editor
 func (a *App) switchEditor(forward bool, wrap bool) error {
 	var index int
+	selectableViews := a.selectableViews()
 
 	if forward {
 		index = a.currentView + 1
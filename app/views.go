@@ -7,8 +7,6 @@ import (
 )
 
 const (
-	viewPart1    = "parts1"
-	viewPart2    = "parts2"
 	viewTLD      = "tlds"
 	viewDomain   = "domains"
 	viewConsole  = "console"
@@ -16,6 +14,7 @@ const (
 	viewKeys     = "keys"
 	viewSave     = "save"
 	viewLoad     = "load"
+	viewMaxPrice = "maxprice"
 )
 
 type viewProperties struct {
@@ -31,35 +30,9 @@ type viewProperties struct {
 }
 
 var vp = map[string]viewProperties{
-	viewPart1: {
-		title:    "Parts 1",
-		text:     "",
-		x1:       0.0,
-		y1:       0.0,
-		x2:       1,
-		y2:       0.1,
-		editor:   &le,
-		editable: true,
-		modal:    false,
-	},
-	viewPart2: {
-		title:    "Parts 2",
-		text:     "",
-		x1:       0.0,
-		y1:       0.1,
-		x2:       1,
-		y2:       0.2,
-		editor:   &le,
-		editable: true,
-		modal:    false,
-	},
 	viewTLD: {
 		title:    "TLDs",
 		text:     "",
-		x1:       0.0,
-		y1:       0.2,
-		x2:       1,
-		y2:       0.3,
 		editor:   &le,
 		editable: true,
 		modal:    false,
@@ -67,10 +40,6 @@ var vp = map[string]viewProperties{
 	viewDomain: {
 		title:    "Available Domains",
 		text:     "",
-		x1:       0.0,
-		y1:       0.3,
-		x2:       1,
-		y2:       0.7,
 		editor:   nil,
 		editable: false,
 		modal:    false,
@@ -99,7 +68,7 @@ var vp = map[string]viewProperties{
 	},
 	viewKeys: {
 		title:    "Keyboard shortcuts",
-		text:     "<CTL>/: find | <CTL>q: quit | <CTL>j: scroll results down | <CTL>k: scroll results up | <CTL>s: save | <CTL>r: toggle TLD substitutions",
+		text:     "<CTL>/: find | <CTL>x: cancel | <CTL>q: quit | <CTL>j: scroll results down | <CTL>k: scroll results up | <CTL>s: save | <CTL>r: toggle TLD substitutions | <CTL>p: toggle premium domains | <CTL>o: sort by price | <CTL>e: max price | <CTL>n: add part | <CTL>d: remove part | <CTL>t: toggle optional part",
 		x1:       0.0,
 		y1:       0.9,
 		x2:       1,
@@ -122,27 +91,69 @@ var vp = map[string]viewProperties{
 		editable: true,
 		modal:    true,
 	},
+	viewMaxPrice: {
+		title:    "Max price (<CTRL>q: quit | <ENTER>: set)",
+		text:     "",
+		editor:   &le,
+		editable: true,
+		modal:    true,
+	},
 }
 
 var views = []string{
-	viewPart1,
-	viewPart2,
-	viewTLD,
-	viewDomain,
 	viewConsole,
 	viewSettings,
 	viewKeys,
 	viewSave,
 }
 
-var selectableViews = []string{
-	viewPart1,
-	viewPart2,
-	viewTLD,
+// Maximum number of part rows
+const maxParts = 9
+
+// Height of the part and TLD rows
+const rowHeight = 3
+
+// editorViews lists all views that can become selectable
+var editorViews = func() []string {
+	names := make([]string, 0, maxParts+1)
+	for i := 0; i < maxParts; i++ {
+		names = append(names, partView(i))
+	}
+
+	return append(names, viewTLD)
+}()
+
+// partView returns the name of the view for the part with index i
+func partView(i int) string {
+	return fmt.Sprintf("parts%d", i+1)
+}
+
+// partProperties returns the view properties of the part with index i
+func (a *App) partProperties(i int) viewProperties {
+	title := fmt.Sprintf("Parts %d", i+1)
+	if a.state.Parts[i].Optional {
+		title += " (optional)"
+	}
+
+	return viewProperties{
+		title:    title,
+		editor:   &le,
+		editable: true,
+	}
+}
+
+// selectableViews returns the views that can be focussed: the parts followed
+// by the TLDs
+func (a *App) selectableViews() []string {
+	return append(append([]string{}, editorViews[:len(a.state.Parts)]...), viewTLD)
 }
 
 // Layout sets up the views
 func (a *App) Layout(g *gocui.Gui) error {
+	if err := a.layoutRows(); err != nil {
+		return err
+	}
+
 	for _, v := range views {
 		if err := a.initView(v); err != nil {
 			return err
@@ -161,6 +172,48 @@ func (a *App) Layout(g *gocui.Gui) error {
 	return nil
 }
 
+// partIndex returns the index of the part shown in a view or -1 if the view
+// doesn't show a part
+func (a *App) partIndex(name string) int {
+	for i := range a.state.Parts {
+		if partView(i) == name {
+			return i
+		}
+	}
+
+	return -1
+}
+
+// layoutRows stacks the part and TLD rows at the top and fits the result list
+// in below them
+func (a *App) layoutRows() error {
+	maxX, maxY := a.gui.Size()
+
+	y := 0
+	for i := range a.state.Parts {
+		name := partView(i)
+		if err := a.createView(name, 0, y, maxX-1, y+rowHeight-1); err != nil {
+			return err
+		}
+		v, _ := a.gui.View(name)
+		v.Title = a.partProperties(i).title
+		y += rowHeight
+	}
+
+	if err := a.createView(viewTLD, 0, y, maxX-1, y+rowHeight-1); err != nil {
+		return err
+	}
+	y += rowHeight
+
+	// Keep the result list visible if there are many parts
+	y2 := int(vp[viewConsole].y1*float64(maxY)) - 1
+	if y2 < y+1 {
+		y2 = y + 1
+	}
+
+	return a.createView(viewDomain, 0, y, maxX-1, y2)
+}
+
 // initView initializes a view
 func (a *App) initView(viewName string) error {
 	maxX, maxY := a.gui.Size()
@@ -187,7 +240,10 @@ func (a *App) createView(viewName string, x1, y1, x2, y2 int) error {
 			return err
 		}
 
-		p := vp[viewName]
+		p, ok := vp[viewName]
+		if !ok {
+			p = a.partProperties(a.partIndex(viewName))
+		}
 		v.Title = p.title
 		v.Editor = p.editor
 		v.Editable = p.editable
@@ -200,7 +256,7 @@ func (a *App) createView(viewName string, x1, y1, x2, y2 int) error {
 
 // setSelectableView set the focus to the view specified by id
 func (a *App) setSelectableView(id int) error {
-	if err := a.setView(selectableViews[id]); err != nil {
+	if err := a.setView(a.selectableViews()[id]); err != nil {
 		return err
 	}
 	a.currentView = id
@@ -226,7 +282,7 @@ func (a *App) clearView(name string) {
 // closeView closes a view
 func (a *App) closeView(name string) {
 	a.gui.DeleteView(name)
-	a.setView(viewPart1)
+	a.setSelectableView(0)
 }
 
 // writeView writes string to view
@@ -0,0 +1,228 @@
+package cli
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/MichaelThessel/gomainr/output"
+	"github.com/MichaelThessel/gomainr/search"
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+// Exit codes
+const (
+	ExitFound          = 0
+	ExitNone           = 1
+	ExitError          = 2
+	ExitUsage          = 3
+	ExitAuth           = 4
+	ExitQuotaExhausted = 5
+	ExitRateLimited    = 6
+	ExitTransient      = 7
+	ExitUnsupportedTLD = 8
+	ExitInvalidDomain  = 9
+)
+
+// Exit codes for source errors of a known kind
+var exitCodes = map[error]int{
+	source.ErrAuth:           ExitAuth,
+	source.ErrQuotaExhausted: ExitQuotaExhausted,
+	source.ErrRateLimited:    ExitRateLimited,
+	source.ErrTransient:      ExitTransient,
+	source.ErrUnsupportedTLD: ExitUnsupportedTLD,
+	source.ErrInvalidDomain:  ExitInvalidDomain,
+}
+
+// Options holds the search options for a headless run
+type Options struct {
+	Parts            []search.Part
+	Tlds             []string
+	TLDSubstitutions bool
+	Format           string
+	Filter           search.Filter
+	SortByPrice      bool
+}
+
+// CLI runs searches without the terminal UI
+type CLI struct {
+	s      *search.Search
+	stdout io.Writer
+	stderr io.Writer
+}
+
+// New returns a new CLI instance
+func New(s *search.Search, stdout, stderr io.Writer) *CLI {
+	c := new(CLI)
+
+	c.s = s
+	c.stdout = stdout
+	c.stderr = stderr
+
+	return c
+}
+
+// Run performs the search and prints the available domains. It returns the
+// exit code for the process.
+func (c *CLI) Run(o *Options) int {
+	if err := c.validate(o); err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return ExitUsage
+	}
+
+	domains := c.s.BuildQuery(o.Parts, o.Tlds, o.TLDSubstitutions)
+	if len(domains) == 0 {
+		fmt.Fprintln(c.stderr, "No possible searches!")
+		return ExitUsage
+	}
+
+	w, err := output.New(o.Format, c.stdout)
+	if err != nil {
+		fmt.Fprintln(c.stderr, err)
+		return ExitUsage
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	found := 0
+	failed := 0
+	kinds := make(map[error]int)
+	var buffered []search.Result
+	for result := range c.s.Run(ctx, domains) {
+		if result.Err != nil {
+			fmt.Fprintf(c.stderr, "%s: %s\n", result.Domain, result.Err)
+			failed++
+			kinds[source.KindOf(result.Err)]++
+		}
+
+		// Hidden domains are skipped entirely
+		if o.Filter.Excludes(result) {
+			continue
+		}
+
+		if result.Agreement == source.Disagreed {
+			fmt.Fprintf(c.stderr, "%s: sources disagree (%s)\n", result.Domain, result.Message)
+		}
+
+		if result.Available() {
+			found++
+		}
+
+		// Sorting requires all results
+		if o.SortByPrice {
+			buffered = append(buffered, result)
+			continue
+		}
+
+		if err := w.Write(result); err != nil {
+			fmt.Fprintln(c.stderr, "Couldn't write output:", err)
+			return ExitError
+		}
+	}
+
+	search.SortByPrice(buffered)
+	for _, result := range buffered {
+		if err := w.Write(result); err != nil {
+			fmt.Fprintln(c.stderr, "Couldn't write output:", err)
+			return ExitError
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		fmt.Fprintln(c.stderr, "Couldn't write output:", err)
+		return ExitError
+	}
+
+	// Failed checks are reported on stderr but don't hide partial results
+	code := ExitNone
+	if failed > 0 {
+		code = c.failed(kinds)
+	}
+	if found > 0 {
+		return ExitFound
+	}
+
+	return code
+}
+
+// failed prints the number of failed domains per error kind and returns the
+// exit code for the most severe kind
+func (c *CLI) failed(kinds map[error]int) int {
+	code := ExitError
+	for _, kind := range source.ErrorKinds {
+		if kinds[kind] == 0 {
+			continue
+		}
+		fmt.Fprintf(c.stderr, "%s: %d domain(s)\n", kind, kinds[kind])
+
+		// Kinds are ordered by severity
+		if code == ExitError {
+			code = exitCodes[kind]
+		}
+	}
+	if kinds[nil] > 0 {
+		fmt.Fprintf(c.stderr, "Other errors: %d domain(s)\n", kinds[nil])
+	}
+
+	return code
+}
+
+// validate validates that the required options are populated
+func (c *CLI) validate(o *Options) error {
+	if !hasWords(o.Parts) {
+		return fmt.Errorf("Parts cannot be empty")
+	}
+
+	if len(o.Tlds) == 0 && !o.TLDSubstitutions {
+		return fmt.Errorf("TLDs cannot be empty")
+	}
+
+	return search.ValidateTlds(o.Tlds)
+}
+
+// hasWords returns true if at least one part has words
+func hasWords(parts []search.Part) bool {
+	for _, part := range parts {
+		if len(part.Words) > 0 {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ParseList splits a space or comma separated list into its unique elements
+func ParseList(list string) []string {
+	parts := strings.FieldsFunc(list, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t' || r == '\n' || r == '\r'
+	})
+
+	m := make(map[string]bool)
+	unique := make([]string, 0, len(parts))
+	for _, part := range parts {
+		if ok := m[part]; !ok {
+			unique = append(unique, part)
+			m[part] = true
+		}
+	}
+
+	return unique
+}
+
+// ReadList reads a whitespace or comma separated list from a reader
+func ReadList(r io.Reader) ([]string, error) {
+	var lines []string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, scanner.Text())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return ParseList(strings.Join(lines, " ")), nil
+}
@@ -0,0 +1,81 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/MichaelThessel/gomainr/cache"
+	"github.com/MichaelThessel/gomainr/output"
+	"github.com/MichaelThessel/gomainr/search"
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+// fakeSource reports domains starting with "free" as available and fails
+// domains starting with "fail", "auth" or "inva" (the latter two with typed
+// errors)
+type fakeSource struct{}
+
+func (f fakeSource) Name() string {
+	return "fake"
+}
+
+func (f fakeSource) IsAvailable(ctx context.Context, domain string) (source.Result, error) {
+	switch {
+	case domain[:4] == "free":
+		return source.Result{Domain: domain, Status: source.StatusAvailable}, nil
+	case domain[:4] == "fail":
+		return source.Result{Domain: domain}, errors.New("Check failed")
+	case domain[:4] == "auth":
+		return source.Result{Domain: domain}, &source.Error{Kind: source.ErrAuth}
+	case domain[:4] == "inva":
+		return source.Result{Domain: domain}, &source.Error{Kind: source.ErrInvalidDomain}
+	}
+
+	return source.Result{Domain: domain, Status: source.StatusTaken}, nil
+}
+
+func TestRunExitCodes(t *testing.T) {
+	tests := []struct {
+		name   string
+		parts  []string
+		tlds   []string
+		code   int
+		stdout string
+	}{
+		{"found", []string{"free", "taken"}, []string{"com"}, ExitFound, "free.com\n"},
+		{"none", []string{"taken"}, []string{"com"}, ExitNone, ""},
+		{"error", []string{"fail", "taken"}, []string{"com"}, ExitError, ""},
+		{"found with errors", []string{"free", "fail"}, []string{"com"}, ExitFound, "free.com\n"},
+		{"invalid domain", []string{"inva", "fail"}, []string{"com"}, ExitInvalidDomain, ""},
+		{"most severe error", []string{"inva", "auth"}, []string{"com"}, ExitAuth, ""},
+		{"no parts", nil, []string{"com"}, ExitUsage, ""},
+		{"no TLDs", []string{"free"}, nil, ExitUsage, ""},
+		{"invalid TLD", []string{"free"}, []string{"invalid"}, ExitUsage, ""},
+	}
+
+	for _, tt := range tests {
+		var stdout, stderr bytes.Buffer
+		s := search.New(fakeSource{}, cache.New(t.TempDir()))
+		s.SetRetries(0)
+
+		code := New(s, &stdout, &stderr).Run(&Options{Parts: []search.Part{{Words: tt.parts}}, Tlds: tt.tlds, Format: output.FormatText})
+		if code != tt.code {
+			t.Errorf("%s: Run() = %d, want %d (stderr: %s)", tt.name, code, tt.code, stderr.String())
+		}
+		if stdout.String() != tt.stdout {
+			t.Errorf("%s: stdout = %q, want %q", tt.name, stdout.String(), tt.stdout)
+		}
+	}
+}
+
+func TestParseList(t *testing.T) {
+	got := ParseList("foo, bar\tbaz foo,,qux")
+	want := []string{"foo", "bar", "baz", "qux"}
+
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ParseList() = %v, want %v", got, want)
+	}
+}
@@ -1,15 +1,19 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 	"io/ioutil"
 	"log"
 	"os"
 	"os/user"
+	"sort"
 
 	"github.com/MichaelThessel/gomainr/app"
 	"github.com/MichaelThessel/gomainr/cache"
+	"github.com/MichaelThessel/gomainr/cli"
 	"github.com/MichaelThessel/gomainr/file"
+	"github.com/MichaelThessel/gomainr/output"
 	"github.com/MichaelThessel/gomainr/search"
 	"github.com/MichaelThessel/gomainr/search/source"
 
@@ -23,14 +27,107 @@ type configPaths struct {
 }
 
 type config struct {
-	DNS       *source.DNSConfig
-	NameCheap *source.NameCheapConfig
-	GoDaddy   *source.GoDaddyConfig
+	Chain     []chainStepConfig
+	Consensus *consensusConfig
+	Route     []routeConfig
+	Search    *searchConfig
+	Sources   map[string]toml.Primitive
+}
+
+// sourceConfig holds the decoded config section of a source instance
+type sourceConfig struct {
+	sourceType string
+	config     interface{}
+	enabled    bool
+}
+
+// sourceHeader holds the settings shared by all source config sections
+type sourceHeader struct {
+	Type    string
+	Enabled bool
+}
+
+type searchConfig struct {
+	Source          string
+	HidePremium     bool
+	MaxPremiumPrice float64
+	MaxPrice        float64
+	Currency        string
+}
+
+type routeConfig struct {
+	TLDs   []string
+	Source string
+}
+
+type consensusConfig struct {
+	Sources []string
+	Enabled bool
+}
+
+type chainStepConfig struct {
+	Source string
+	Role   string
+}
+
+type flags struct {
+	headless         bool
+	parts1           string
+	parts2           string
+	parts            []partFlag
+	tlds             string
+	tldSubstitutions bool
+	format           string
+	hidePremium      bool
+	maxPremiumPrice  float64
+	maxPrice         float64
+	sortByPrice      bool
+}
+
+// partFlag is a part list passed with -part or -opt-part
+type partFlag struct {
+	value    string
+	optional bool
+}
+
+// partFlags collects repeated -part and -opt-part flags in order
+type partFlags struct {
+	parts    *[]partFlag
+	optional bool
+}
+
+// String returns the flag's default value
+func (p partFlags) String() string {
+	return ""
+}
+
+// Set adds a part list
+func (p partFlags) Set(value string) error {
+	*p.parts = append(*p.parts, partFlag{value, p.optional})
+	return nil
 }
 
 var c *config
 var a *app.App
 var cp *configPaths
+var f *flags
+var sources = make(map[string]source.Source)
+var sourceConfigs = make(map[string]sourceConfig)
+
+// Order in which enabled sources are preferred as default source
+var sourcePriority = []string{
+	source.DNSSource,
+	source.RDAPSource,
+	source.WHOISSource,
+	source.ZoneFileSource,
+	source.ExecSource,
+	source.NameCheapSource,
+	source.GoDaddySource,
+	source.PorkbunSource,
+	source.GandiSource,
+	source.NameComSource,
+	source.CloudflareSource,
+}
 
 func init() {
 	if err := initPaths(); err != nil {
@@ -40,21 +137,44 @@ func init() {
 	if err := generateConfig(); err != nil {
 		log.Panic(err)
 	}
+}
+
+func main() {
+	parseFlags()
 
 	if err := loadConfig(); err != nil {
-		log.Panic(err)
+		exitConfigError(err)
+	}
+
+	s, err := initSearch()
+	if err != nil {
+		exitConfigError(err)
+	}
+
+	if f.headless {
+		os.Exit(runHeadless(s))
 	}
-}
 
-func main() {
-	s := initSearch()
 	a = app.New(s)
+	a.SetFilter(filter())
 	defer a.Close()
 
 	// Main loop
 	a.Loop()
 }
 
+// exitConfigError reports an invalid config file and exits
+func exitConfigError(err error) {
+	// Keep config errors apart from empty results in headless mode
+	if f.headless {
+		fmt.Fprintf(os.Stderr, "%s please update: %s\n", err, cp.configFile)
+		os.Exit(cli.ExitUsage)
+	}
+
+	fmt.Printf("%s please update: %s\n", err, cp.configFile)
+	os.Exit(1)
+}
+
 // initPaths sets config and data storage paths
 func initPaths() error {
 	configFile := "config"
@@ -75,22 +195,288 @@ func initPaths() error {
 	return nil
 }
 
+// parseFlags parses the command line flags
+func parseFlags() {
+	f = new(flags)
+
+	flag.BoolVar(&f.headless, "headless", false, "Run a search without the terminal UI")
+	flag.StringVar(&f.parts1, "parts1", "", "Space or comma separated list of domain parts (\"-\" reads from stdin)")
+	flag.StringVar(&f.parts2, "parts2", "", "Space or comma separated list of domain parts (\"-\" reads from stdin)")
+	flag.Var(partFlags{&f.parts, false}, "part", "Space or comma separated list of domain parts, repeat for more parts (\"-\" reads from stdin)")
+	flag.Var(partFlags{&f.parts, true}, "opt-part", "Like -part but the part may also be left out")
+	flag.StringVar(&f.tlds, "tlds", "", "Space or comma separated list of TLDs (\"-\" reads from stdin)")
+	flag.BoolVar(&f.tldSubstitutions, "sub", false, "Enable TLD substitutions")
+	flag.StringVar(&f.format, "format", output.FormatText, "Output format (text, jsonl, csv, tsv)")
+	flag.BoolVar(&f.hidePremium, "hide-premium", false, "Hide premium domains")
+	flag.Float64Var(&f.maxPremiumPrice, "max-premium-price", 0, "Hide premium domains above this price")
+	flag.Float64Var(&f.maxPrice, "max-price", 0, "Hide domains above this price")
+	flag.BoolVar(&f.sortByPrice, "sort-price", false, "Sort results by price (results are printed once the search is complete)")
+	flag.Parse()
+}
+
+// runHeadless runs a search without the terminal UI and returns the exit code
+func runHeadless(s *search.Search) int {
+	o := &cli.Options{
+		TLDSubstitutions: f.tldSubstitutions,
+		Format:           f.format,
+		Filter:           filter(),
+		SortByPrice:      f.sortByPrice,
+	}
+
+	if f.hidePremium {
+		o.Filter.HidePremium = true
+	}
+	if f.maxPremiumPrice > 0 {
+		o.Filter.MaxPremiumPrice = int64(f.maxPremiumPrice * 1000000)
+	}
+	if f.maxPrice > 0 {
+		o.Filter.MaxPrice = int64(f.maxPrice * 1000000)
+	}
+
+	// -parts1 and -parts2 come before the parts passed with -part
+	parts := append([]partFlag{{value: f.parts1}, {value: f.parts2}}, f.parts...)
+	o.Parts = make([]search.Part, len(parts))
+
+	type list struct {
+		value  string
+		target *[]string
+	}
+
+	var lists []list
+	for i, p := range parts {
+		o.Parts[i].Optional = p.optional
+		lists = append(lists, list{p.value, &o.Parts[i].Words})
+	}
+	lists = append(lists, list{f.tlds, &o.Tlds})
+
+	stdinUsed := false
+	for _, l := range lists {
+		if l.value != "-" {
+			*l.target = cli.ParseList(l.value)
+			continue
+		}
+
+		if stdinUsed {
+			fmt.Fprintln(os.Stderr, "Only one list can be read from stdin")
+			return cli.ExitUsage
+		}
+		stdinUsed = true
+
+		words, err := cli.ReadList(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "Couldn't read from stdin:", err)
+			return cli.ExitUsage
+		}
+		*l.target = words
+	}
+
+	return cli.New(s, os.Stdout, os.Stderr).Run(o)
+}
+
+// filter returns the result filter from the config
+func filter() search.Filter {
+	if c.Search == nil {
+		return search.Filter{}
+	}
+
+	return search.Filter{
+		HidePremium:     c.Search.HidePremium,
+		MaxPremiumPrice: int64(c.Search.MaxPremiumPrice * 1000000),
+		MaxPrice:        int64(c.Search.MaxPrice * 1000000),
+		Currency:        c.Search.Currency,
+	}
+}
+
 // initSearch initializes the searcher
-func initSearch() *search.Search {
-	var searchSource source.Source
-	if c.DNS != nil && c.DNS.Enabled {
-		searchSource = source.Get(c.DNS, source.DNSSource)
-	} else if c.NameCheap != nil && c.NameCheap.Enabled {
-		searchSource = source.Get(c.NameCheap, source.NameCheapSource)
-	} else if c.GoDaddy != nil && c.GoDaddy.Enabled {
-		searchSource = source.Get(c.GoDaddy, source.GoDaddySource)
-	} else {
-		fmt.Println("No search source enabled please update:", cp.configFile)
-		os.Exit(1)
+func initSearch() (*search.Search, error) {
+	searchSource, err := initSource()
+	if err != nil {
+		return nil, err
 	}
 	cache := cache.New(cp.dataDir)
 
-	return search.New(searchSource, cache)
+	return search.New(searchSource, cache), nil
+}
+
+// initSource initializes the search source
+func initSource() (source.Source, error) {
+	defaultSource, err := initDefaultSource()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(c.Route) > 0 {
+		return initRouter(defaultSource)
+	}
+
+	if defaultSource == nil {
+		return nil, fmt.Errorf("No search source enabled")
+	}
+
+	return defaultSource, nil
+}
+
+// initDefaultSource initializes the source used for all domains without a
+// route. It returns nil if no source is enabled.
+func initDefaultSource() (source.Source, error) {
+	if c.Search != nil && c.Search.Source != "" {
+		return sourceByName(c.Search.Source)
+	}
+
+	switch {
+	case len(c.Chain) > 0:
+		return sourceByName("chain")
+	case c.Consensus != nil && c.Consensus.Enabled:
+		return sourceByName("consensus")
+	}
+
+	if names := enabledSources(); len(names) > 0 {
+		return sourceByName(names[0])
+	}
+
+	return nil, nil
+}
+
+// enabledSources returns the names of all enabled sources. Sources are sorted
+// by sourcePriority, named instances come last.
+func enabledSources() []string {
+	rank := func(name string) int {
+		for i, p := range sourcePriority {
+			if p == name {
+				return i
+			}
+		}
+		return len(sourcePriority)
+	}
+
+	names := make([]string, 0, len(sourceConfigs))
+	for name, sc := range sourceConfigs {
+		if sc.enabled {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	sort.SliceStable(names, func(i, j int) bool {
+		return rank(names[i]) < rank(names[j])
+	})
+
+	return names
+}
+
+// initRouter initializes the per TLD routing
+func initRouter(defaultSource source.Source) (source.Source, error) {
+	routes := make([]search.Route, 0, len(c.Route))
+	for _, route := range c.Route {
+		s, err := sourceByName(route.Source)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid route: %s", err)
+		}
+
+		routes = append(routes, search.Route{Patterns: route.TLDs, Source: s})
+	}
+
+	return search.NewRouter(routes, defaultSource)
+}
+
+// initChain initializes the source chain
+func initChain() (source.Source, error) {
+	steps := make([]source.ChainStep, 0, len(c.Chain))
+	for _, step := range c.Chain {
+		if step.Source == "chain" {
+			return nil, fmt.Errorf("Invalid source chain: Chain can't include itself")
+		}
+
+		s, err := sourceByName(step.Source)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid source chain: %s", err)
+		}
+
+		role := step.Role
+		if role == "" {
+			role = source.RoleCheck
+		}
+
+		steps = append(steps, source.ChainStep{Source: s, Role: role})
+	}
+
+	chain, err := source.NewChain(steps)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid source chain: %s", err)
+	}
+
+	return chain, nil
+}
+
+// initConsensus initializes the consensus source
+func initConsensus() (source.Source, error) {
+	sources := make([]source.Source, 0, len(c.Consensus.Sources))
+	for _, name := range c.Consensus.Sources {
+		if name == "consensus" {
+			return nil, fmt.Errorf("Invalid consensus: Consensus can't include itself")
+		}
+
+		s, err := sourceByName(name)
+		if err != nil {
+			return nil, fmt.Errorf("Invalid consensus: %s", err)
+		}
+		sources = append(sources, s)
+	}
+
+	consensus, err := source.NewConsensus(sources)
+	if err != nil {
+		return nil, fmt.Errorf("Invalid consensus: %s", err)
+	}
+
+	return consensus, nil
+}
+
+// sourceByName returns the source for a config section. Sources are shared
+// so that rate limits apply across chains, routes and consensus.
+func sourceByName(name string) (source.Source, error) {
+	if s, ok := sources[name]; ok {
+		return s, nil
+	}
+
+	s, err := newSource(name)
+	if err != nil {
+		return nil, err
+	}
+	sources[name] = s
+
+	return s, nil
+}
+
+// newSource creates the source for a config section
+func newSource(name string) (source.Source, error) {
+	switch name {
+	case "chain":
+		if len(c.Chain) > 0 {
+			return initChain()
+		}
+		return nil, fmt.Errorf("Source not configured: %s", name)
+	case "consensus":
+		if c.Consensus != nil {
+			return initConsensus()
+		}
+		return nil, fmt.Errorf("Source not configured: %s", name)
+	}
+
+	sc, ok := sourceConfigs[name]
+	if !ok {
+		return nil, fmt.Errorf("Unknown source: %s", name)
+	}
+
+	s, err := source.Get(sc.config, sc.sourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	// Named instances report their own name to tell them apart
+	if name != sc.sourceType {
+		s = source.WithName(s, name)
+	}
+
+	return s, nil
 }
 
 // generateConfig generates config files and directories
@@ -120,10 +506,75 @@ func loadConfig() error {
 		return err
 	}
 
-	if _, err := toml.Decode(string(configData), &c); err != nil {
+	md, err := toml.Decode(string(configData), &c)
+	if err != nil {
 		return err
 	}
 
+	// Named source instances
+	for name, prim := range c.Sources {
+		if name == "chain" || name == "consensus" {
+			return fmt.Errorf("Invalid source name: %s", name)
+		}
+
+		var header sourceHeader
+		if err := md.PrimitiveDecode(prim, &header); err != nil {
+			return err
+		}
+		if header.Type == "" {
+			return fmt.Errorf("Missing type for source: %s", name)
+		}
+
+		if err := addSourceConfig(md, prim, name, header.Type); err != nil {
+			return err
+		}
+	}
+
+	// Sections named after a source type
+	var sections map[string]toml.Primitive
+	md, err = toml.Decode(string(configData), &sections)
+	if err != nil {
+		return err
+	}
+
+	for name, prim := range sections {
+		if !source.IsRegistered(name) {
+			continue
+		}
+
+		if _, ok := sourceConfigs[name]; ok {
+			return fmt.Errorf("Duplicate source: %s", name)
+		}
+
+		if err := addSourceConfig(md, prim, name, name); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// addSourceConfig decodes the config section of a source instance
+func addSourceConfig(md toml.MetaData, prim toml.Primitive, name, sourceType string) error {
+	config, err := source.NewConfig(sourceType)
+	if err != nil {
+		return fmt.Errorf("Invalid source %s: %s", name, err)
+	}
+	if err := md.PrimitiveDecode(prim, config); err != nil {
+		return fmt.Errorf("Invalid source %s: %s", name, err)
+	}
+
+	var header sourceHeader
+	if err := md.PrimitiveDecode(prim, &header); err != nil {
+		return fmt.Errorf("Invalid source %s: %s", name, err)
+	}
+
+	sourceConfigs[name] = sourceConfig{
+		sourceType: sourceType,
+		config:     config,
+		enabled:    header.Enabled,
+	}
+
 	return nil
 }
 
@@ -149,17 +600,70 @@ func createConfigFile(fileName string) error {
 
 // initConfig writes the default config to config file
 func initConfig(fd *os.File) error {
-	defaultConfig := `[dns]
+	defaultConfig := `[search]
+Source = ""
+HidePremium = false
+MaxPremiumPrice = 0
+MaxPrice = 0
+[dns]
 Enabled = true
+CacheSize = 0
+Timeout = 5
+RecordTypes = ["TXT"]
+Resolvers = []
 [namecheap]
 APIUser = ""
 APIToken = ""
 UserName = ""
+ClientIP = ""
 Enabled = false
+Sandbox = false
+RateLimit = 0.33
+RateBurst = 1
+MaxRetries = 3
+RetryDelay = 3000
+Timeout = 30
 [godaddy]
 Key = ""
 Secret = ""
 Enabled = false
+PremiumPrice = 100
+Sandbox = false
+RateLimit = 1
+RateBurst = 1
+MaxRetries = 3
+RetryDelay = 1000
+Timeout = 30
+[porkbun]
+APIKey = ""
+SecretAPIKey = ""
+Enabled = false
+[gandi]
+APIKey = ""
+Enabled = false
+[namecom]
+UserName = ""
+Token = ""
+Enabled = false
+[cloudflare]
+AccountID = ""
+APIToken = ""
+Enabled = false
+[rdap]
+Enabled = false
+BootstrapFile = ""
+[whois]
+Enabled = false
+Timeout = 10
+[zonefile]
+Enabled = false
+Files = []
+[exec]
+Enabled = false
+Command = ""
+Args = []
+Batch = false
+Timeout = 30
 `
 	_, err := fd.WriteString(defaultConfig)
 	return err
@@ -0,0 +1,213 @@
+package output
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MichaelThessel/gomainr/search"
+)
+
+// Output formats
+const (
+	FormatText  = "text"
+	FormatJSONL = "jsonl"
+	FormatCSV   = "csv"
+	FormatTSV   = "tsv"
+)
+
+var header = []string{
+	"domain",
+	"status",
+	"source",
+	"cached",
+	"price",
+	"renewal_price",
+	"period",
+	"currency",
+	"checked_at",
+	"agreement",
+	"message",
+	"error",
+}
+
+// Writer is the interface for result writers
+type Writer interface {
+	Write(search.Result) error
+	Flush() error
+}
+
+// record is the machine-readable representation of a result
+type record struct {
+	Domain       string `json:"domain"`
+	Status       string `json:"status"`
+	Source       string `json:"source"`
+	Cached       bool   `json:"cached"`
+	Price        string `json:"price,omitempty"`
+	RenewalPrice string `json:"renewal_price,omitempty"`
+	Period       int    `json:"period,omitempty"`
+	Currency     string `json:"currency,omitempty"`
+	CheckedAt    string `json:"checked_at"`
+	Agreement    string `json:"agreement,omitempty"`
+	Message      string `json:"message,omitempty"`
+	Error        string `json:"error,omitempty"`
+}
+
+// New returns a writer for the given format
+func New(format string, w io.Writer) (Writer, error) {
+	switch format {
+	case FormatText:
+		return &textWriter{w: w}, nil
+	case FormatJSONL:
+		return &jsonlWriter{enc: json.NewEncoder(w)}, nil
+	case FormatCSV:
+		return newCSVWriter(w, ','), nil
+	case FormatTSV:
+		return newCSVWriter(w, '\t'), nil
+	default:
+		return nil, fmt.Errorf("Invalid output format: %s", format)
+	}
+}
+
+// FormatFromFile returns the export format matching the extension of a file
+// name or an empty string if there is none
+func FormatFromFile(fileName string) string {
+	switch strings.ToLower(filepath.Ext(fileName)) {
+	case ".jsonl":
+		return FormatJSONL
+	case ".csv":
+		return FormatCSV
+	case ".tsv":
+		return FormatTSV
+	default:
+		return ""
+	}
+}
+
+// newRecord converts a result into a record
+func newRecord(r search.Result) record {
+	rec := record{
+		Domain:    r.Domain,
+		Status:    r.Status.String(),
+		Source:    r.Source,
+		Cached:    r.Cached,
+		CheckedAt: r.CheckedAt.Format(time.RFC3339),
+		Agreement: string(r.Agreement),
+		Message:   r.Message,
+	}
+
+	if r.Err != nil {
+		rec.Error = r.Err.Error()
+	}
+
+	if r.Price > 0 {
+		rec.Price = FormatPrice(r.Price)
+		rec.Period = r.Period
+		rec.Currency = r.Currency
+	}
+
+	if r.RenewalPrice > 0 {
+		rec.RenewalPrice = FormatPrice(r.RenewalPrice)
+	}
+
+	return rec
+}
+
+// FormatPrice formats a price in micro-units as decimal
+func FormatPrice(micros int64) string {
+	return strconv.FormatFloat(float64(micros)/1000000, 'f', 2, 64)
+}
+
+// textWriter writes the names of available domains, one per line
+type textWriter struct {
+	w io.Writer
+}
+
+// Write writes a result
+func (tw *textWriter) Write(r search.Result) error {
+	if !r.Available() {
+		return nil
+	}
+
+	_, err := fmt.Fprintln(tw.w, r.Domain)
+	return err
+}
+
+// Flush flushes buffered output
+func (tw *textWriter) Flush() error {
+	return nil
+}
+
+// jsonlWriter writes one JSON object per result
+type jsonlWriter struct {
+	enc *json.Encoder
+}
+
+// Write writes a result
+func (jw *jsonlWriter) Write(r search.Result) error {
+	return jw.enc.Encode(newRecord(r))
+}
+
+// Flush flushes buffered output
+func (jw *jsonlWriter) Flush() error {
+	return nil
+}
+
+// csvWriter writes one delimited row per result
+type csvWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+// newCSVWriter returns a csvWriter using the given delimiter
+func newCSVWriter(w io.Writer, delimiter rune) *csvWriter {
+	cw := new(csvWriter)
+
+	cw.w = csv.NewWriter(w)
+	cw.w.Comma = delimiter
+
+	return cw
+}
+
+// Write writes a result
+func (cw *csvWriter) Write(r search.Result) error {
+	if !cw.headerWritten {
+		if err := cw.w.Write(header); err != nil {
+			return err
+		}
+		cw.headerWritten = true
+	}
+
+	rec := newRecord(r)
+
+	period := ""
+	if rec.Period > 0 {
+		period = strconv.Itoa(rec.Period)
+	}
+
+	return cw.w.Write([]string{
+		rec.Domain,
+		rec.Status,
+		rec.Source,
+		strconv.FormatBool(rec.Cached),
+		rec.Price,
+		rec.RenewalPrice,
+		period,
+		rec.Currency,
+		rec.CheckedAt,
+		rec.Agreement,
+		rec.Message,
+		rec.Error,
+	})
+}
+
+// Flush flushes buffered output
+func (cw *csvWriter) Flush() error {
+	cw.w.Flush()
+	return cw.w.Error()
+}
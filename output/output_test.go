@@ -0,0 +1,102 @@
+package output
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/MichaelThessel/gomainr/search"
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+var checkedAt = time.Date(2020, 1, 2, 3, 4, 5, 0, time.UTC)
+
+var testResults = []search.Result{
+	{
+		Result:    source.Result{Domain: "foo.com", Status: source.StatusAvailable, Price: 12990000, RenewalPrice: 14990000, Period: 1, Currency: "USD", Source: "godaddy"},
+		CheckedAt: checkedAt,
+	},
+	{
+		Result:    source.Result{Domain: "bar.com", Status: source.StatusTaken, Source: "dns+rdap", Agreement: source.Disagreed, Message: "rdap: available"},
+		Cached:    true,
+		CheckedAt: checkedAt,
+	},
+	{
+		Result:    source.Result{Domain: "baz.com", Source: "godaddy"},
+		CheckedAt: checkedAt,
+		Err:       errors.New("Check failed"),
+	},
+}
+
+func TestWriters(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{FormatText, "foo.com\n"},
+		{
+			FormatJSONL,
+			`{"domain":"foo.com","status":"available","source":"godaddy","cached":false,"price":"12.99","renewal_price":"14.99","period":1,"currency":"USD","checked_at":"2020-01-02T03:04:05Z"}` + "\n" +
+				`{"domain":"bar.com","status":"taken","source":"dns+rdap","cached":true,"checked_at":"2020-01-02T03:04:05Z","agreement":"disagree","message":"rdap: available"}` + "\n" +
+				`{"domain":"baz.com","status":"unknown","source":"godaddy","cached":false,"checked_at":"2020-01-02T03:04:05Z","error":"Check failed"}` + "\n",
+		},
+		{
+			FormatCSV,
+			"domain,status,source,cached,price,renewal_price,period,currency,checked_at,agreement,message,error\n" +
+				"foo.com,available,godaddy,false,12.99,14.99,1,USD,2020-01-02T03:04:05Z,,,\n" +
+				"bar.com,taken,dns+rdap,true,,,,,2020-01-02T03:04:05Z,disagree,rdap: available,\n" +
+				"baz.com,unknown,godaddy,false,,,,,2020-01-02T03:04:05Z,,,Check failed\n",
+		},
+		{
+			FormatTSV,
+			"domain\tstatus\tsource\tcached\tprice\trenewal_price\tperiod\tcurrency\tchecked_at\tagreement\tmessage\terror\n" +
+				"foo.com\tavailable\tgodaddy\tfalse\t12.99\t14.99\t1\tUSD\t2020-01-02T03:04:05Z\t\t\t\n" +
+				"bar.com\ttaken\tdns+rdap\ttrue\t\t\t\t\t2020-01-02T03:04:05Z\tdisagree\trdap: available\t\n" +
+				"baz.com\tunknown\tgodaddy\tfalse\t\t\t\t\t2020-01-02T03:04:05Z\t\t\tCheck failed\n",
+		},
+	}
+
+	for _, tt := range tests {
+		var buf bytes.Buffer
+		w, err := New(tt.format, &buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		for _, r := range testResults {
+			if err := w.Write(r); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if err := w.Flush(); err != nil {
+			t.Fatal(err)
+		}
+
+		if buf.String() != tt.want {
+			t.Errorf("%s: got\n%s\nwant\n%s", tt.format, buf.String(), tt.want)
+		}
+	}
+}
+
+func TestInvalidFormat(t *testing.T) {
+	if _, err := New("xml", new(bytes.Buffer)); err == nil {
+		t.Error("New() error = nil, want error")
+	}
+}
+
+func TestFormatFromFile(t *testing.T) {
+	tests := map[string]string{
+		"results.jsonl": FormatJSONL,
+		"results.CSV":   FormatCSV,
+		"results.tsv":   FormatTSV,
+		"session.json":  "",
+		"session":       "",
+	}
+
+	for file, want := range tests {
+		if got := FormatFromFile(file); got != want {
+			t.Errorf("FormatFromFile(%s) = %q, want %q", file, got, want)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+const (
+	defaultWorkers = 4
+	defaultRetries = 2
+	retryDelay     = 500 * time.Millisecond
+)
+
+// SetWorkers sets the number of concurrent workers used by Run
+func (s *Search) SetWorkers(workers int) {
+	if workers < 1 {
+		workers = 1
+	}
+	s.workers = workers
+}
+
+// SetRetries sets how often a failed check is retried before the domain is
+// reported as unknown
+func (s *Search) SetRetries(retries int) {
+	if retries < 0 {
+		retries = 0
+	}
+	s.retries = retries
+}
+
+// unsupportedTLDs tracks the TLDs the source reported as unsupported during a
+// search so the remaining domains of these TLDs aren't checked
+type unsupportedTLDs struct {
+	mu   sync.Mutex
+	errs map[string]error
+}
+
+// get returns the error the TLD of a domain was marked with or nil
+func (u *unsupportedTLDs) get(domain string) error {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	return u.errs[domainTLD(domain)]
+}
+
+// mark marks the TLD of a domain as unsupported if err says so
+func (u *unsupportedTLDs) mark(domain string, err error) {
+	if !errors.Is(err, source.ErrUnsupportedTLD) {
+		return
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	u.errs[domainTLD(domain)] = err
+}
+
+// domainTLD returns everything after the first label of a domain
+func domainTLD(domain string) string {
+	if i := strings.Index(domain, "."); i != -1 {
+		return strings.ToLower(domain[i+1:])
+	}
+
+	return ""
+}
+
+// Run checks the availability of the given domains concurrently and streams
+// the results. The returned channel is closed once all domains have been
+// checked or the context is cancelled.
+func (s *Search) Run(ctx context.Context, domains []string) <-chan Result {
+	jobs := make(chan []string)
+	results := make(chan Result)
+	unsupported := &unsupportedTLDs{errs: make(map[string]error)}
+
+	var wg sync.WaitGroup
+	for i := 0; i < s.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.work(ctx, jobs, results, unsupported)
+		}()
+	}
+
+	// Queue the domains
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		defer close(jobs)
+		s.queue(ctx, domains, jobs, results)
+	}()
+
+	// Close the result channel once all workers are done
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	return results
+}
+
+// queue splits the domains into jobs. If the source supports batch checks
+// cached results are sent directly and the remaining domains are grouped
+// into batches.
+func (s *Search) queue(ctx context.Context, domains []string, jobs chan<- []string, results chan<- Result) {
+	bs, ok := s.source.(source.BatchSource)
+	if !ok {
+		for _, domain := range domains {
+			select {
+			case jobs <- []string{domain}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		return
+	}
+
+	size := bs.BatchSize()
+	if size < 1 {
+		size = 1
+	}
+
+	batch := make([]string, 0, size)
+	for _, domain := range domains {
+		if result, ok := s.loadCache(domain); ok {
+			result.Domain = domain
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		batch = append(batch, domain)
+		if len(batch) < size {
+			continue
+		}
+
+		select {
+		case jobs <- batch:
+		case <-ctx.Done():
+			return
+		}
+		batch = make([]string, 0, size)
+	}
+
+	if len(batch) > 0 {
+		select {
+		case jobs <- batch:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// work processes jobs until the job queue is drained or the context is
+// cancelled. Errors are reported per domain and don't stop the worker.
+func (s *Search) work(ctx context.Context, jobs <-chan []string, results chan<- Result, unsupported *unsupportedTLDs) {
+	bs, batch := s.source.(source.BatchSource)
+
+	for job := range jobs {
+		if ctx.Err() != nil {
+			return
+		}
+
+		var jobResults []Result
+		if batch {
+			jobResults = s.checkBatch(ctx, bs, job, unsupported)
+		} else {
+			jobResults = []Result{s.check(ctx, job[0], unsupported)}
+		}
+
+		for _, result := range jobResults {
+			select {
+			case results <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}
+}
+
+// check checks a single domain
+func (s *Search) check(ctx context.Context, domain string, unsupported *unsupportedTLDs) Result {
+	if err := unsupported.get(domain); err != nil {
+		return s.failed(domain, err)
+	}
+
+	result, err := s.IsAvailable(ctx, domain)
+
+	return s.retry(ctx, domain, result, err, unsupported)
+}
+
+// retry retries a failed check of a single domain with an increasing delay.
+// Permanent errors and errors the source already retried aren't retried.
+func (s *Search) retry(ctx context.Context, domain string, result Result, err error, unsupported *unsupportedTLDs) Result {
+	for attempt := 1; err != nil && shouldRetry(err) && attempt <= s.retries; attempt++ {
+		if !wait(ctx, time.Duration(attempt)*retryDelay) {
+			break
+		}
+		result, err = s.IsAvailable(ctx, domain)
+	}
+	unsupported.mark(domain, err)
+
+	result.Domain = domain
+	if err != nil {
+		result.Status = source.StatusUnknown
+		result.Err = err
+	}
+
+	return result
+}
+
+// checkBatch checks a batch of domains retrying failed batches with an
+// increasing delay. Domains that failed within a successful batch are retried
+// one by one. Permanent errors and errors the source already retried aren't
+// retried.
+func (s *Search) checkBatch(ctx context.Context, bs source.BatchSource, domains []string, unsupported *unsupportedTLDs) []Result {
+	var skipped []Result
+	pending := make([]string, 0, len(domains))
+	for _, domain := range domains {
+		if err := unsupported.get(domain); err != nil {
+			skipped = append(skipped, s.failed(domain, err))
+			continue
+		}
+		pending = append(pending, domain)
+	}
+	if len(pending) == 0 {
+		return skipped
+	}
+
+	var results []Result
+	var err error
+
+	for attempt := 1; ; attempt++ {
+		results, err = s.isAvailableBatch(ctx, bs, pending)
+		if err == nil || !shouldRetry(err) || attempt > s.retries || !wait(ctx, time.Duration(attempt)*retryDelay) {
+			break
+		}
+	}
+
+	if err == nil {
+		for i, result := range results {
+			if result.Err != nil {
+				results[i] = s.retry(ctx, result.Domain, result, result.Err, unsupported)
+			}
+		}
+		return append(skipped, results...)
+	}
+
+	// Report the error for every domain of the batch
+	results = make([]Result, 0, len(pending))
+	for _, domain := range pending {
+		results = append(results, s.failed(domain, err))
+	}
+
+	return append(skipped, results...)
+}
+
+// shouldRetry returns true if a check that failed with err should be retried.
+// Sources with rate limits retry requests themselves, retrying their failures
+// again would only get the API keys throttled.
+func shouldRetry(err error) bool {
+	return !source.IsPermanent(err) && !source.IsRetried(err)
+}
+
+// failed returns the result for a domain that couldn't be checked
+func (s *Search) failed(domain string, err error) Result {
+	return Result{
+		Result: source.Result{
+			Domain: domain,
+			Source: s.source.Name(),
+		},
+		CheckedAt: time.Now(),
+		Err:       err,
+	}
+}
+
+// wait waits for the given duration. It returns false if the context was
+// cancelled in the meantime.
+func wait(ctx context.Context, d time.Duration) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
@@ -0,0 +1,324 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/MichaelThessel/gomainr/cache"
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+// fakeSource reports domains starting with "free" as available. check
+// overrides the answer if set. Checks are counted per domain.
+type fakeSource struct {
+	name  string
+	delay time.Duration
+	check func(domain string, call int) (source.Result, error)
+
+	mu    sync.Mutex
+	calls map[string]int
+}
+
+func (f *fakeSource) Name() string {
+	if f.name == "" {
+		return "fake"
+	}
+
+	return f.name
+}
+
+func (f *fakeSource) IsAvailable(ctx context.Context, domain string) (source.Result, error) {
+	f.mu.Lock()
+	if f.calls == nil {
+		f.calls = make(map[string]int)
+	}
+	f.calls[domain]++
+	call := f.calls[domain]
+	f.mu.Unlock()
+
+	time.Sleep(f.delay)
+
+	if f.check != nil {
+		return f.check(domain, call)
+	}
+	if strings.HasPrefix(domain, "free") {
+		return source.Result{Domain: domain, Status: source.StatusAvailable}, nil
+	}
+
+	return source.Result{Domain: domain, Status: source.StatusTaken}, nil
+}
+
+// checks returns the number of checks of a domain
+func (f *fakeSource) checks(domain string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.calls[domain]
+}
+
+// fakeBatchSource checks domains in batches of size using fakeSource. The
+// batches are recorded.
+type fakeBatchSource struct {
+	fakeSource
+	size    int
+	batches [][]string
+}
+
+func (f *fakeBatchSource) BatchSize() int {
+	return f.size
+}
+
+func (f *fakeBatchSource) IsAvailableBatch(ctx context.Context, domains []string) ([]source.BatchResult, error) {
+	f.mu.Lock()
+	f.batches = append(f.batches, domains)
+	f.mu.Unlock()
+
+	results := make([]source.BatchResult, 0, len(domains))
+	for _, domain := range domains {
+		r, err := f.IsAvailable(ctx, domain)
+		results = append(results, source.BatchResult{Result: r, Err: err})
+	}
+
+	return results, nil
+}
+
+// newTestSearch returns a Search with an empty cache
+func newTestSearch(t *testing.T, s source.Source) *Search {
+	return New(s, cache.New(t.TempDir()))
+}
+
+// collect returns the results of a search by domain
+func collect(results <-chan Result) map[string]Result {
+	byDomain := make(map[string]Result)
+	for r := range results {
+		byDomain[r.Domain] = r
+	}
+
+	return byDomain
+}
+
+func TestRun(t *testing.T) {
+	domains := []string{"free1.com", "taken1.com", "free2.com", "taken2.com", "free3.com"}
+
+	results := collect(newTestSearch(t, new(fakeSource)).Run(context.Background(), domains))
+
+	if len(results) != len(domains) {
+		t.Fatalf("Run() returned %d results, want %d", len(results), len(domains))
+	}
+	for _, domain := range domains {
+		r, ok := results[domain]
+		if !ok {
+			t.Errorf("Run() is missing %s", domain)
+			continue
+		}
+		if want := strings.HasPrefix(domain, "free"); r.Available() != want {
+			t.Errorf("Run() %s available = %v, want %v", domain, r.Available(), want)
+		}
+	}
+}
+
+func TestRunCancel(t *testing.T) {
+	domains := make([]string, 100)
+	for i := range domains {
+		domains[i] = strings.Repeat("x", i+1) + ".com"
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	s := newTestSearch(t, &fakeSource{delay: 20 * time.Millisecond})
+	results := s.Run(ctx, domains)
+
+	<-results
+	cancel()
+
+	done := make(chan int)
+	go func() {
+		n := 1
+		for range results {
+			n++
+		}
+		done <- n
+	}()
+
+	select {
+	case n := <-done:
+		if n >= len(domains) {
+			t.Errorf("Run() returned %d results after cancelling, want fewer than %d", n, len(domains))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Run() didn't stop after cancelling")
+	}
+}
+
+func TestRunRetry(t *testing.T) {
+	fs := &fakeSource{check: func(domain string, call int) (source.Result, error) {
+		if domain == "broken.com" || call == 1 {
+			return source.Result{Domain: domain}, errors.New("Check failed")
+		}
+		return source.Result{Domain: domain, Status: source.StatusAvailable}, nil
+	}}
+
+	s := newTestSearch(t, fs)
+	s.SetRetries(1)
+
+	results := collect(s.Run(context.Background(), []string{"flaky.com", "broken.com"}))
+
+	if r := results["flaky.com"]; r.Err != nil || !r.Available() || fs.checks("flaky.com") != 2 {
+		t.Errorf("flaky.com = %+v after %d checks, want available after 2 checks", r, fs.checks("flaky.com"))
+	}
+	if r := results["broken.com"]; r.Err == nil || r.Status != source.StatusUnknown || fs.checks("broken.com") != 2 {
+		t.Errorf("broken.com = %+v after %d checks, want error after 2 checks", r, fs.checks("broken.com"))
+	}
+}
+
+func TestRunBatch(t *testing.T) {
+	fs := &fakeBatchSource{size: 2}
+	s := newTestSearch(t, fs)
+	s.saveCache("cached.com", Result{
+		Result: source.Result{Domain: "cached.com", Status: source.StatusAvailable, Source: "fake"},
+	})
+
+	domains := []string{"free1.com", "cached.com", "taken1.com", "free2.com"}
+	results := collect(s.Run(context.Background(), domains))
+
+	if len(results) != len(domains) {
+		t.Fatalf("got %d results, want %d", len(results), len(domains))
+	}
+	if r := results["cached.com"]; !r.Cached || !r.Available() {
+		t.Errorf("cached.com = %+v, want cached available result", r)
+	}
+	if r := results["free2.com"]; r.Cached || !r.Available() {
+		t.Errorf("free2.com = %+v, want available result", r)
+	}
+
+	checked := 0
+	for _, batch := range fs.batches {
+		if len(batch) > fs.size {
+			t.Errorf("batch %v exceeds the batch size %d", batch, fs.size)
+		}
+		for _, domain := range batch {
+			if domain == "cached.com" {
+				t.Errorf("cached domain was sent in batch %v", batch)
+			}
+			checked++
+		}
+	}
+	if checked != 3 {
+		t.Errorf("%d domains were checked in batches, want 3", checked)
+	}
+}
+
+func TestCachePerSource(t *testing.T) {
+	c := cache.New(t.TempDir())
+
+	a := New(&fakeSource{name: "a"}, c)
+	a.saveCache("free.com", Result{Result: source.Result{Domain: "free.com", Status: source.StatusAvailable, Source: "a"}})
+
+	if _, ok := a.loadCache("free.com"); !ok {
+		t.Error("Result wasn't cached")
+	}
+	if _, ok := New(&fakeSource{name: "b"}, c).loadCache("free.com"); ok {
+		t.Error("Result of source a was served for source b")
+	}
+}
+
+func TestRunBatchRetry(t *testing.T) {
+	fs := &fakeBatchSource{size: 10}
+	fs.check = func(domain string, call int) (source.Result, error) {
+		if call == 1 {
+			return source.Result{Domain: domain}, errors.New("Check failed")
+		}
+		return source.Result{Domain: domain, Status: source.StatusTaken}, nil
+	}
+
+	s := newTestSearch(t, fs)
+	s.SetRetries(1)
+
+	results := collect(s.Run(context.Background(), []string{"a.com", "b.com"}))
+
+	for _, domain := range []string{"a.com", "b.com"} {
+		if r := results[domain]; r.Err != nil || r.Status != source.StatusTaken {
+			t.Errorf("%s = %+v, want taken after retrying the domain", domain, r)
+		}
+	}
+	if len(fs.batches) != 1 {
+		t.Errorf("%d batches were sent, want 1", len(fs.batches))
+	}
+}
+
+func TestRunSourceRetried(t *testing.T) {
+	var requests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	s := newTestSearch(t, source.NewPorkbun(&source.PorkbunConfig{
+		BaseURL:     server.URL,
+		LimitConfig: source.LimitConfig{RateLimit: -1, MaxRetries: 1, RetryDelay: 1},
+	}))
+	s.SetRetries(2)
+
+	results := collect(s.Run(context.Background(), []string{"free.com"}))
+
+	// The source retried the request already, the engine doesn't retry again
+	if r := results["free.com"]; r.Err == nil || !source.IsRetried(r.Err) {
+		t.Errorf("free.com = %+v, want retried error", r)
+	}
+	if n := atomic.LoadInt32(&requests); n != 2 {
+		t.Errorf("%d requests were sent, want 2", n)
+	}
+}
+
+func TestRunPermanentError(t *testing.T) {
+	fs := &fakeSource{check: func(domain string, call int) (source.Result, error) {
+		return source.Result{Domain: domain}, &source.Error{Kind: source.ErrAuth}
+	}}
+
+	s := newTestSearch(t, fs)
+	s.SetRetries(2)
+
+	results := collect(s.Run(context.Background(), []string{"free.com"}))
+
+	if r := results["free.com"]; !errors.Is(r.Err, source.ErrAuth) || fs.checks("free.com") != 1 {
+		t.Errorf("free.com = %+v after %d checks, want authentication error after 1 check", r, fs.checks("free.com"))
+	}
+}
+
+func TestRunUnsupportedTLD(t *testing.T) {
+	fs := &fakeSource{check: func(domain string, call int) (source.Result, error) {
+		if strings.HasSuffix(domain, ".xyz") {
+			return source.Result{Domain: domain}, &source.Error{Kind: source.ErrUnsupportedTLD}
+		}
+		return source.Result{Domain: domain, Status: source.StatusTaken}, nil
+	}}
+
+	s := newTestSearch(t, fs)
+	s.SetWorkers(1)
+
+	domains := []string{"a.xyz", "b.xyz", "c.xyz", "a.com"}
+	results := collect(s.Run(context.Background(), domains))
+
+	for _, domain := range domains[:3] {
+		if r := results[domain]; !errors.Is(r.Err, source.ErrUnsupportedTLD) {
+			t.Errorf("%s = %+v, want unsupported TLD error", domain, r)
+		}
+	}
+	if r := results["a.com"]; r.Err != nil || r.Status != source.StatusTaken {
+		t.Errorf("a.com = %+v, want taken", r)
+	}
+
+	// The TLD isn't checked again once the source reported it as unsupported
+	if n := fs.checks("b.xyz") + fs.checks("c.xyz"); n != 0 {
+		t.Errorf("Domains of the unsupported TLD were checked %d more times, want 0", n)
+	}
+}
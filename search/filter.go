@@ -0,0 +1,61 @@
+package search
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+// Filter hides available domains from search results based on their price
+type Filter struct {
+	HidePremium     bool   // Hide all premium domains
+	MaxPremiumPrice int64  // Hide premium domains above this price in micro-units (0 disables)
+	MaxPrice        int64  // Hide domains above this price in micro-units (0 disables)
+	Currency        string // Currency of the max prices (empty compares prices in any currency)
+}
+
+// Excludes returns true if a result is hidden by the filter. Domains with an
+// unknown price or a price in another currency are never hidden by the max
+// prices.
+func (f Filter) Excludes(r Result) bool {
+	comparable := f.Currency == "" || r.Currency == "" || strings.EqualFold(f.Currency, r.Currency)
+
+	if comparable && f.MaxPrice > 0 && r.Price > f.MaxPrice {
+		return true
+	}
+
+	if r.Status != source.StatusPremium {
+		return false
+	}
+
+	if f.HidePremium {
+		return true
+	}
+
+	return comparable && f.MaxPremiumPrice > 0 && r.Price > f.MaxPremiumPrice
+}
+
+// SortByPrice sorts results by price. Prices in different currencies aren't
+// comparable so results are grouped by currency first. Results with an
+// unknown price are sorted last. Results with the same price are sorted by
+// domain.
+func SortByPrice(results []Result) {
+	sort.SliceStable(results, func(i, j int) bool {
+		pi, pj := results[i].Price, results[j].Price
+		switch {
+		case pi == 0 && pj == 0:
+			return results[i].Domain < results[j].Domain
+		case pi == 0:
+			return false
+		case pj == 0:
+			return true
+		case results[i].Currency != results[j].Currency:
+			return results[i].Currency < results[j].Currency
+		case pi == pj:
+			return results[i].Domain < results[j].Domain
+		}
+
+		return pi < pj
+	})
+}
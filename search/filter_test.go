@@ -0,0 +1,71 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+// result returns a result with the given price in whole US dollars
+func result(domain string, status source.Status, price int64) Result {
+	return priced(domain, status, price, "USD")
+}
+
+// priced returns a result with the given price in whole units of currency
+func priced(domain string, status source.Status, price int64, currency string) Result {
+	return Result{Result: source.Result{Domain: domain, Status: status, Price: price * 1000000, Currency: currency}}
+}
+
+func TestFilterExcludes(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter Filter
+		result Result
+		want   bool
+	}{
+		{"no filter", Filter{}, result("a.com", source.StatusPremium, 5000), false},
+		{"hide premium", Filter{HidePremium: true}, result("a.com", source.StatusPremium, 5), true},
+		{"hide premium keeps regular domains", Filter{HidePremium: true}, result("a.com", source.StatusAvailable, 5), false},
+		{"premium above max premium price", Filter{MaxPremiumPrice: 100000000}, result("a.com", source.StatusPremium, 101), true},
+		{"premium at max premium price", Filter{MaxPremiumPrice: 100000000}, result("a.com", source.StatusPremium, 100), false},
+		{"max premium price ignores regular domains", Filter{MaxPremiumPrice: 100000000}, result("a.com", source.StatusAvailable, 500), false},
+		{"above max price", Filter{MaxPrice: 20000000}, result("a.com", source.StatusAvailable, 21), true},
+		{"below max price", Filter{MaxPrice: 20000000}, result("a.com", source.StatusAvailable, 19), false},
+		{"unknown price", Filter{MaxPrice: 20000000}, result("a.com", source.StatusAvailable, 0), false},
+		{"max price applies to premium domains", Filter{MaxPrice: 20000000}, result("a.com", source.StatusPremium, 21), true},
+		{"above max price in currency", Filter{MaxPrice: 20000000, Currency: "USD"}, result("a.com", source.StatusAvailable, 21), true},
+		{"other currency", Filter{MaxPrice: 20000000, Currency: "USD"}, priced("a.com", source.StatusAvailable, 21, "EUR"), false},
+		{"premium in other currency", Filter{MaxPremiumPrice: 100000000, Currency: "USD"}, priced("a.com", source.StatusPremium, 101, "EUR"), false},
+	}
+
+	for _, tt := range tests {
+		if got := tt.filter.Excludes(tt.result); got != tt.want {
+			t.Errorf("%s: Excludes() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSortByPrice(t *testing.T) {
+	results := []Result{
+		result("d.com", source.StatusAvailable, 0),
+		result("c.com", source.StatusAvailable, 20),
+		priced("f.com", source.StatusAvailable, 5, "EUR"),
+		result("b.com", source.StatusAvailable, 10),
+		result("a.com", source.StatusAvailable, 0),
+		result("e.com", source.StatusAvailable, 10),
+	}
+
+	SortByPrice(results)
+
+	var got []string
+	for _, r := range results {
+		got = append(got, r.Domain)
+	}
+
+	// Grouped by currency, unknown prices last, same prices by domain
+	want := []string{"f.com", "b.com", "e.com", "c.com", "a.com", "d.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SortByPrice() = %v, want %v", got, want)
+	}
+}
@@ -2,20 +2,18 @@ package search
 
 import "strings"
 
-// BuildQuery builds domain names from given parts
-func (s *Search) BuildQuery(first []string, second []string, tlds []string, tldSubstitutions bool) []string {
-	var baseDomains []string
-
-	// Build all possible combinations of first and second level
-	for i := 0; i < len(first); i++ {
-		if len(second) == 0 {
-			baseDomains = append(baseDomains, first[i])
-		} else {
-			for j := 0; j < len(second); j++ {
-				baseDomains = append(baseDomains, first[i]+second[j])
-			}
-		}
-	}
+// Part is a list of words for one position of the domain name, i.e. a list of
+// prefixes
+type Part struct {
+	Words    []string
+	Optional bool // Also build names without a word of this part
+}
+
+// BuildQuery builds domain names from given parts. The base domains are all
+// combinations of one word from each part in order. Parts without words are
+// skipped.
+func (s *Search) BuildQuery(parts []Part, tlds []string, tldSubstitutions bool) []string {
+	baseDomains := combine(parts)
 
 	// Append TLDs
 	var domains []string
@@ -33,6 +31,42 @@ func (s *Search) BuildQuery(first []string, second []string, tlds []string, tldS
 	return domains
 }
 
+// combine returns the cartesian product of the parts' words
+func combine(parts []Part) []string {
+	combinations := []string{""}
+	for _, part := range parts {
+		if len(part.Words) == 0 {
+			continue
+		}
+
+		words := part.Words
+		if part.Optional {
+			words = append([]string{""}, words...)
+		}
+
+		next := make([]string, 0, len(combinations)*len(words))
+		for _, combination := range combinations {
+			for _, word := range words {
+				next = append(next, combination+word)
+			}
+		}
+		combinations = next
+	}
+
+	// Optional parts can yield empty and duplicate names
+	m := make(map[string]bool)
+	baseDomains := make([]string, 0, len(combinations))
+	for _, combination := range combinations {
+		if combination == "" || m[combination] {
+			continue
+		}
+		baseDomains = append(baseDomains, combination)
+		m[combination] = true
+	}
+
+	return baseDomains
+}
+
 // tldSub substites the end of a base domain with a TLD if the end matches a
 // existing TLD.
 // i.e: superyachts super.yachts
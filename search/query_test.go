@@ -0,0 +1,93 @@
+package search
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestBuildQuery(t *testing.T) {
+	tests := []struct {
+		name  string
+		parts []Part
+		tlds  []string
+		want  []string
+	}{
+		{
+			name:  "two parts",
+			parts: []Part{{Words: []string{"foo", "bar"}}, {Words: []string{"alice", "bob"}}},
+			tlds:  []string{"com", "net"},
+			want: []string{
+				"fooalice.com", "fooalice.net", "foobob.com", "foobob.net",
+				"baralice.com", "baralice.net", "barbob.com", "barbob.net",
+			},
+		},
+		{
+			name:  "empty parts are skipped",
+			parts: []Part{{Words: []string{"foo", "bar"}}, {}},
+			tlds:  []string{"com"},
+			want:  []string{"foo.com", "bar.com"},
+		},
+		{
+			name: "three parts",
+			parts: []Part{
+				{Words: []string{"get"}},
+				{Words: []string{"cloud", "box"}},
+				{Words: []string{"hq"}},
+			},
+			tlds: []string{"io"},
+			want: []string{"getcloudhq.io", "getboxhq.io"},
+		},
+		{
+			name: "optional parts",
+			parts: []Part{
+				{Words: []string{"get"}, Optional: true},
+				{Words: []string{"cloud"}},
+				{Words: []string{"hq"}, Optional: true},
+			},
+			tlds: []string{"com"},
+			want: []string{"cloud.com", "cloudhq.com", "getcloud.com", "getcloudhq.com"},
+		},
+		{
+			name:  "only optional parts",
+			parts: []Part{{Words: []string{"a"}, Optional: true}, {Words: []string{"b"}, Optional: true}},
+			tlds:  []string{"com"},
+			want:  []string{"b.com", "a.com", "ab.com"},
+		},
+		{
+			name:  "duplicate names",
+			parts: []Part{{Words: []string{"ab", "a"}}, {Words: []string{"c", "bc"}}},
+			tlds:  []string{"com"},
+			want:  []string{"abc.com", "abbc.com", "ac.com"},
+		},
+		{
+			name:  "no words",
+			parts: []Part{{}, {Optional: true}},
+			tlds:  []string{"com"},
+			want:  nil,
+		},
+	}
+
+	s := new(Search)
+	for _, tt := range tests {
+		got := s.BuildQuery(tt.parts, tt.tlds, false)
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: BuildQuery() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestBuildQueryTLDSubstitutions(t *testing.T) {
+	got := new(Search).BuildQuery([]Part{{Words: []string{"fishnet"}}}, []string{"com"}, true)
+
+	want := map[string]bool{"fishnet.com": false, "fish.net": false}
+	for _, domain := range got {
+		if _, ok := want[domain]; ok {
+			want[domain] = true
+		}
+	}
+	for domain, found := range want {
+		if !found {
+			t.Errorf("BuildQuery() = %v, missing %s", got, domain)
+		}
+	}
+}
@@ -0,0 +1,155 @@
+package search
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+const routerBatchSize = 50
+
+// Route maps TLD patterns (i.e. "io", "co.*", "xn--*") to a source
+type Route struct {
+	Patterns []string
+	Source   source.Source
+}
+
+// Router checks domains using the source of the first route matching the
+// TLD of a domain
+type Router struct {
+	routes   []Route
+	fallback source.Source
+}
+
+// NewRouter returns a new Router. Domains that don't match any route are
+// checked with the fallback source (may be nil).
+func NewRouter(routes []Route, fallback source.Source) (*Router, error) {
+	for _, route := range routes {
+		for _, pattern := range route.Patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				return nil, fmt.Errorf("Invalid TLD pattern: %s", pattern)
+			}
+		}
+	}
+
+	r := new(Router)
+
+	r.routes = routes
+	r.fallback = fallback
+
+	return r, nil
+}
+
+// Name returns the name of the source
+func (r *Router) Name() string {
+	return "router"
+}
+
+// Key returns a key identifying the routes and their sources
+func (r *Router) Key() string {
+	keys := make([]string, 0, len(r.routes)+1)
+	for _, route := range r.routes {
+		keys = append(keys, strings.Join(route.Patterns, "|")+":"+source.Key(route.Source))
+	}
+	if r.fallback != nil {
+		keys = append(keys, "*:"+source.Key(r.fallback))
+	}
+
+	return "router(" + strings.Join(keys, ",") + ")"
+}
+
+// IsAvailable checks if a domain is available
+func (r *Router) IsAvailable(ctx context.Context, domain string) (source.Result, error) {
+	s := r.route(domain)
+	if s == nil {
+		return source.Result{Domain: domain}, &source.Error{Kind: source.ErrUnsupportedTLD, Message: "No source for " + domain}
+	}
+
+	result, err := s.IsAvailable(ctx, domain)
+	if result.Source == "" {
+		result.Source = s.Name()
+	}
+
+	return result, err
+}
+
+// BatchSize returns the maximum number of domains per batch. Batches are only
+// used if a route's source supports them so other sources are still checked
+// by multiple workers.
+func (r *Router) BatchSize() int {
+	sources := []source.Source{r.fallback}
+	for _, route := range r.routes {
+		sources = append(sources, route.Source)
+	}
+
+	for _, s := range sources {
+		if _, ok := s.(source.BatchSource); ok {
+			return routerBatchSize
+		}
+	}
+
+	return 1
+}
+
+// IsAvailableBatch checks the availability of multiple domains. The domains
+// are grouped by route and each group is checked with its source.
+func (r *Router) IsAvailableBatch(ctx context.Context, domains []string) ([]source.BatchResult, error) {
+	var sources []source.Source
+	groups := make(map[source.Source][]string)
+
+	var results []source.BatchResult
+	for _, domain := range domains {
+		s := r.route(domain)
+		if s == nil {
+			results = append(results, source.BatchResult{
+				Result: source.Result{Domain: domain},
+				Err:    &source.Error{Kind: source.ErrUnsupportedTLD, Message: "No source for " + domain},
+			})
+			continue
+		}
+
+		if _, ok := groups[s]; !ok {
+			sources = append(sources, s)
+		}
+		groups[s] = append(groups[s], domain)
+	}
+
+	for _, s := range sources {
+		results = append(results, source.CheckBatch(ctx, s, groups[s])...)
+	}
+
+	return results, nil
+}
+
+// route returns the source for a domain
+func (r *Router) route(domain string) source.Source {
+	tlds := suffixes(domain)
+
+	for _, route := range r.routes {
+		for _, pattern := range route.Patterns {
+			for _, tld := range tlds {
+				if ok, _ := path.Match(strings.ToLower(pattern), tld); ok {
+					return route.Source
+				}
+			}
+		}
+	}
+
+	return r.fallback
+}
+
+// suffixes returns the possible TLDs of a domain, longest first
+// i.e: foo.co.uk -> co.uk, uk
+func suffixes(domain string) []string {
+	labels := strings.Split(strings.ToLower(domain), ".")
+
+	tlds := make([]string, 0, len(labels))
+	for i := 1; i < len(labels); i++ {
+		tlds = append(tlds, strings.Join(labels[i:], "."))
+	}
+
+	return tlds
+}
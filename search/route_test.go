@@ -0,0 +1,93 @@
+package search
+
+import (
+	"context"
+	"testing"
+
+	"github.com/MichaelThessel/gomainr/search/source"
+)
+
+func TestRouterRoute(t *testing.T) {
+	io := &fakeSource{name: "io"}
+	co := &fakeSource{name: "co"}
+	idn := &fakeSource{name: "idn"}
+	fallback := &fakeSource{name: "fallback"}
+
+	r, err := NewRouter([]Route{
+		{Patterns: []string{"io"}, Source: io},
+		{Patterns: []string{"co.*", "CO"}, Source: co},
+		{Patterns: []string{"xn--*"}, Source: idn},
+	}, fallback)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		domain string
+		want   source.Source
+	}{
+		{"foo.io", io},
+		{"FOO.IO", io},
+		{"foo.co", co},
+		{"foo.co.uk", co},
+		{"foo.com", fallback},
+		{"foo.xn--p1ai", idn},
+		{"foo.uk", fallback},
+	}
+
+	for _, tt := range tests {
+		if got := r.route(tt.domain); got != tt.want {
+			t.Errorf("route(%s) = %v, want %v", tt.domain, got, tt.want)
+		}
+	}
+}
+
+func TestRouterNoFallback(t *testing.T) {
+	r, err := NewRouter([]Route{{Patterns: []string{"io"}, Source: &fakeSource{name: "io"}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.IsAvailable(context.Background(), "foo.com"); err == nil {
+		t.Error("IsAvailable() error = nil, want error")
+	}
+}
+
+func TestRouterInvalidPattern(t *testing.T) {
+	if _, err := NewRouter([]Route{{Patterns: []string{"[io"}, Source: &fakeSource{name: "io"}}}, nil); err == nil {
+		t.Error("NewRouter() error = nil, want error")
+	}
+}
+
+func TestRouterBatchSize(t *testing.T) {
+	tests := []struct {
+		name     string
+		routed   source.Source
+		fallback source.Source
+		want     int
+	}{
+		{"no batch sources", &fakeSource{name: "io"}, &fakeSource{name: "fallback"}, 1},
+		{"batch route", &fakeBatchSource{fakeSource: fakeSource{name: "io"}, size: 10}, nil, routerBatchSize},
+		{"batch fallback", &fakeSource{name: "io"}, &fakeBatchSource{fakeSource: fakeSource{name: "fallback"}, size: 10}, routerBatchSize},
+	}
+
+	for _, tt := range tests {
+		r, err := NewRouter([]Route{{Patterns: []string{"io"}, Source: tt.routed}}, tt.fallback)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if got := r.BatchSize(); got != tt.want {
+			t.Errorf("%s: BatchSize() = %d, want %d", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestRouterKey(t *testing.T) {
+	a, _ := NewRouter([]Route{{Patterns: []string{"io"}, Source: &fakeSource{name: "rdap"}}}, &fakeSource{name: "dns"})
+	b, _ := NewRouter([]Route{{Patterns: []string{"io"}, Source: &fakeSource{name: "whois"}}}, &fakeSource{name: "dns"})
+
+	if source.Key(a) == source.Key(b) {
+		t.Errorf("Routers with different sources share the key %s", source.Key(a))
+	}
+}
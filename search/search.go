@@ -1,14 +1,33 @@
 package search
 
 import (
+	"context"
+	"crypto/sha1"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
 	"github.com/MichaelThessel/gomainr/cache"
 	"github.com/MichaelThessel/gomainr/search/source"
 )
 
 // Search struct
 type Search struct {
-	cache  *cache.Cache
-	source source.Source
+	cache   *cache.Cache
+	source  source.Source
+	workers int
+	retries int
+}
+
+// Result holds the availability information for a domain along with
+// details about how it was obtained
+type Result struct {
+	source.Result
+	Cached    bool
+	CheckedAt time.Time
+	Err       error `json:"-"`
 }
 
 var cacheTTL int64 = 86400
@@ -19,37 +38,115 @@ func New(source source.Source, cache *cache.Cache) *Search {
 
 	s.source = source
 	s.cache = cache
+	s.workers = defaultWorkers
+	s.retries = defaultRetries
 
 	return s
 }
 
 // IsAvailable checks the availability of a domain
-func (s *Search) IsAvailable(domain string) (bool, error) {
-	var available bool
-
+func (s *Search) IsAvailable(ctx context.Context, domain string) (Result, error) {
 	// Try to load results from cache
-	cached, err := s.cache.Get(domain)
-	if err == nil && len(cached) > 0 {
-		if cached[0] == 't' {
-			return true, nil
-		}
-		return false, nil
+	if result, ok := s.loadCache(domain); ok {
+		return result, nil
 	}
 
 	// Fetch from API and save to cache
-	available, err = s.source.IsAvailable(domain)
+	result := Result{CheckedAt: time.Now()}
+
+	var err error
+	result.Result, err = s.source.IsAvailable(ctx, domain)
+	if result.Source == "" {
+		result.Source = s.source.Name()
+	}
 	if err != nil {
-		return false, err
+		return result, err
 	}
 
 	// Cache response
-	cached = make([]byte, 1, 1)
-	if available {
-		cached[0] = 't'
-	} else {
-		cached[0] = 'f'
+	if result.Status != source.StatusUnknown {
+		s.saveCache(domain, result)
+	}
+
+	return result, nil
+}
+
+// isAvailableBatch checks the availability of multiple domains using a source
+// that supports batch checks. Failed domains are reported through Result.Err.
+func (s *Search) isAvailableBatch(ctx context.Context, bs source.BatchSource, domains []string) ([]Result, error) {
+	checkedAt := time.Now()
+
+	batchResults, err := bs.IsAvailableBatch(ctx, domains)
+	if err != nil {
+		return nil, err
+	}
+
+	byDomain := make(map[string]source.BatchResult, len(batchResults))
+	for _, br := range batchResults {
+		byDomain[strings.ToLower(br.Domain)] = br
+	}
+
+	results := make([]Result, 0, len(domains))
+	for _, domain := range domains {
+		result := Result{CheckedAt: checkedAt}
+
+		br, ok := byDomain[strings.ToLower(domain)]
+		switch {
+		case !ok:
+			result.Err = errors.New("No result returned")
+		case br.Err != nil:
+			result.Source = br.Source
+			result.Err = br.Err
+		default:
+			result.Result = br.Result
+		}
+		result.Domain = domain
+		if result.Source == "" {
+			result.Source = s.source.Name()
+		}
+
+		if result.Err == nil && result.Status != source.StatusUnknown {
+			s.saveCache(domain, result)
+		}
+
+		results = append(results, result)
+	}
+
+	return results, nil
+}
+
+// cacheKey returns the cache key of a domain. Results are cached per source
+// so switching sources (i.e. from DNS to a chain) or changing the composition
+// of a chain doesn't serve stale answers.
+func (s *Search) cacheKey(domain string) string {
+	return fmt.Sprintf("%x@%s", sha1.Sum([]byte(source.Key(s.source))), domain)
+}
+
+// loadCache loads a result from cache
+func (s *Search) loadCache(domain string) (Result, bool) {
+	var result Result
+
+	cached, err := s.cache.Get(s.cacheKey(domain))
+	if err != nil || len(cached) == 0 {
+		return result, false
+	}
+
+	// Entries that can't be decoded (i.e. from older versions) are treated
+	// as cache misses
+	if err := json.Unmarshal(cached, &result); err != nil {
+		return result, false
+	}
+	result.Cached = true
+
+	return result, true
+}
+
+// saveCache saves a result to cache
+func (s *Search) saveCache(domain string, result Result) {
+	cached, err := json.Marshal(result)
+	if err != nil {
+		return
 	}
-	s.cache.Save(domain, cached, cacheTTL)
 
-	return available, nil
+	s.cache.Save(s.cacheKey(domain), cached, cacheTTL)
 }
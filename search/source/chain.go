@@ -0,0 +1,262 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Chain roles
+const (
+	// RoleFilter sources answer negative results. Domains they report as
+	// available are passed on to the next source.
+	RoleFilter = "filter"
+	// RoleCheck sources answer all domains they return a result for
+	RoleCheck = "check"
+	// RoleFallback sources are only consulted for domains a previous source
+	// failed to check
+	RoleFallback = "fallback"
+)
+
+const chainBatchSize = 50
+
+// Number of concurrent checks of sources that don't support batches
+const checkWorkers = 4
+
+// ChainStep is a source and its role in a chain
+type ChainStep struct {
+	Source Source
+	Role   string
+}
+
+// Chain checks domains against an ordered list of sources
+type Chain struct {
+	steps []ChainStep
+}
+
+// chainState tracks the progress of a domain through the chain
+type chainState struct {
+	final      *Result
+	tentative  *Result
+	err        error
+	unresolved bool
+}
+
+// NewChain returns a new Chain instance
+func NewChain(steps []ChainStep) (Source, error) {
+	if len(steps) == 0 {
+		return nil, errors.New("Source chain is empty")
+	}
+
+	for _, step := range steps {
+		switch step.Role {
+		case RoleFilter, RoleCheck, RoleFallback:
+		default:
+			return nil, fmt.Errorf("Invalid source role: %s", step.Role)
+		}
+	}
+
+	c := new(Chain)
+
+	c.steps = steps
+
+	return c, nil
+}
+
+// Name returns the name of the source
+func (c *Chain) Name() string {
+	return "chain"
+}
+
+// Key returns a key identifying the sources of the chain and their roles
+func (c *Chain) Key() string {
+	keys := make([]string, 0, len(c.steps))
+	for _, step := range c.steps {
+		keys = append(keys, step.Role+":"+Key(step.Source))
+	}
+
+	return "chain(" + strings.Join(keys, ",") + ")"
+}
+
+// IsAvailable checks if a domain is available
+func (c *Chain) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	results, err := c.IsAvailableBatch(ctx, []string{domain})
+	if err != nil {
+		return Result{Domain: domain}, err
+	}
+
+	return results[0].Result, results[0].Err
+}
+
+// BatchSize returns the maximum number of domains per batch
+func (c *Chain) BatchSize() int {
+	size := 1
+	for _, step := range c.steps {
+		if _, ok := step.Source.(BatchSource); ok {
+			size = chainBatchSize
+		}
+	}
+
+	return size
+}
+
+// IsAvailableBatch checks the availability of multiple domains by passing
+// them through the chain
+func (c *Chain) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	states := make(map[string]*chainState, len(domains))
+	for _, domain := range domains {
+		states[strings.ToLower(domain)] = new(chainState)
+	}
+
+	for _, step := range c.steps {
+		var pending []string
+		for _, domain := range domains {
+			st := states[strings.ToLower(domain)]
+			if st.final != nil {
+				continue
+			}
+			if step.Role == RoleFallback && !st.unresolved {
+				continue
+			}
+			pending = append(pending, domain)
+		}
+
+		answered := make(map[string]bool, len(pending))
+		for _, br := range CheckBatch(ctx, step.Source, pending) {
+			st, ok := states[strings.ToLower(br.Domain)]
+			if !ok {
+				continue
+			}
+			answered[strings.ToLower(br.Domain)] = true
+
+			if br.Err != nil || br.Status == StatusUnknown {
+				if br.Err != nil {
+					st.err = br.Err
+				}
+				if step.Role != RoleFilter {
+					st.unresolved = true
+				}
+				continue
+			}
+
+			result := br.Result
+			if step.Role == RoleFilter && result.Available() {
+				st.tentative = &result
+				continue
+			}
+
+			st.final = &result
+		}
+
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		// Domains the source didn't return a result for are treated like
+		// failed checks
+		for _, domain := range pending {
+			if answered[strings.ToLower(domain)] {
+				continue
+			}
+
+			st := states[strings.ToLower(domain)]
+			st.err = errors.New("No result returned")
+			if step.Role != RoleFilter {
+				st.unresolved = true
+			}
+		}
+	}
+
+	results := make([]BatchResult, 0, len(domains))
+	for _, domain := range domains {
+		st := states[strings.ToLower(domain)]
+
+		br := BatchResult{Result: Result{Domain: domain}}
+		switch {
+		case st.final != nil:
+			br.Result = *st.final
+		case st.unresolved && st.err != nil:
+			br.Err = st.err
+		case st.tentative != nil:
+			br.Result = *st.tentative
+		case st.err != nil:
+			br.Err = st.err
+		}
+		br.Domain = domain
+
+		results = append(results, br)
+	}
+
+	return results, nil
+}
+
+// CheckBatch checks the domains against a single source using batch requests if
+// the source supports them. Otherwise the domains are checked concurrently.
+func CheckBatch(ctx context.Context, source Source, domains []string) []BatchResult {
+	bs, batch := source.(BatchSource)
+	if !batch || len(domains) < 2 {
+		return withSource(source, checkEach(ctx, source, domains))
+	}
+
+	var results []BatchResult
+
+	size := bs.BatchSize()
+	if size < 1 {
+		size = 1
+	}
+
+	for start := 0; start < len(domains); start += size {
+		end := start + size
+		if end > len(domains) {
+			end = len(domains)
+		}
+
+		brs, err := bs.IsAvailableBatch(ctx, domains[start:end])
+		if err != nil {
+			for _, domain := range domains[start:end] {
+				results = append(results, BatchResult{Result: Result{Domain: domain}, Err: err})
+			}
+			continue
+		}
+		results = append(results, brs...)
+	}
+
+	return withSource(source, results)
+}
+
+// checkEach checks the domains one by one using up to checkWorkers concurrent
+// checks
+func checkEach(ctx context.Context, source Source, domains []string) []BatchResult {
+	results := make([]BatchResult, len(domains))
+	sem := make(chan struct{}, checkWorkers)
+
+	var wg sync.WaitGroup
+	for i, domain := range domains {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(i int, domain string) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			result, err := source.IsAvailable(ctx, domain)
+			result.Domain = domain
+			results[i] = BatchResult{Result: result, Err: err}
+		}(i, domain)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// withSource sets the source name of results that don't have one
+func withSource(source Source, results []BatchResult) []BatchResult {
+	for i := range results {
+		if results[i].Source == "" {
+			results[i].Source = source.Name()
+		}
+	}
+
+	return results
+}
@@ -0,0 +1,166 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeSource answers domains with the configured statuses. Domains without a
+// status fail.
+type fakeSource struct {
+	name     string
+	statuses map[string]Status
+
+	mu      sync.Mutex
+	checked []string
+}
+
+func (f *fakeSource) Name() string {
+	return f.name
+}
+
+func (f *fakeSource) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	f.mu.Lock()
+	f.checked = append(f.checked, domain)
+	f.mu.Unlock()
+
+	status, ok := f.statuses[domain]
+	if !ok {
+		return Result{Domain: domain}, errors.New("Check failed")
+	}
+
+	return Result{Domain: domain, Status: status, Definitive: true}, nil
+}
+
+// fakeBatchSource answers batches with fakeSource. Domains without a status
+// are left out of the response.
+type fakeBatchSource struct {
+	fakeSource
+}
+
+func (f *fakeBatchSource) BatchSize() int {
+	return 10
+}
+
+func (f *fakeBatchSource) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	var results []BatchResult
+	for _, domain := range domains {
+		if _, ok := f.statuses[domain]; !ok {
+			continue
+		}
+
+		result, err := f.IsAvailable(ctx, domain)
+		results = append(results, BatchResult{Result: result, Err: err})
+	}
+
+	return results, nil
+}
+
+func TestChainRoles(t *testing.T) {
+	filter := &fakeSource{name: "filter", statuses: map[string]Status{
+		"taken.com": StatusTaken,
+		"free.com":  StatusAvailable,
+		"maybe.com": StatusAvailable,
+	}}
+	check := &fakeSource{name: "check", statuses: map[string]Status{
+		"free.com": StatusAvailable,
+	}}
+	fallback := &fakeSource{name: "fallback", statuses: map[string]Status{
+		"maybe.com": StatusTaken,
+	}}
+
+	c, err := NewChain([]ChainStep{
+		{Source: filter, Role: RoleFilter},
+		{Source: check, Role: RoleCheck},
+		{Source: fallback, Role: RoleFallback},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := c.(BatchSource).IsAvailableBatch(context.Background(), []string{"taken.com", "free.com", "maybe.com", "broken.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		status  Status
+		source  string
+		wantErr bool
+	}{
+		{StatusTaken, "filter", false},
+		{StatusAvailable, "check", false},
+		{StatusTaken, "fallback", false},
+		{StatusUnknown, "", true},
+	}
+
+	for i, tt := range tests {
+		br := results[i]
+		if (br.Err != nil) != tt.wantErr || br.Status != tt.status || br.Source != tt.source {
+			t.Errorf("%s = %s from %q (error %v), want %s from %q", br.Domain, br.Status, br.Source, br.Err, tt.status, tt.source)
+		}
+	}
+
+	// Filtered domains aren't passed on
+	for _, domain := range check.checked {
+		if domain == "taken.com" {
+			t.Error("Domain taken according to the filter was checked again")
+		}
+	}
+	if len(fallback.checked) != 2 {
+		t.Errorf("Fallback checked %v, want the 2 unresolved domains", fallback.checked)
+	}
+}
+
+func TestChainMissingResult(t *testing.T) {
+	batch := &fakeBatchSource{fakeSource{name: "batch", statuses: map[string]Status{
+		"a.com": StatusTaken,
+	}}}
+	fallback := &fakeSource{name: "fallback", statuses: map[string]Status{
+		"b.com": StatusAvailable,
+	}}
+
+	c, err := NewChain([]ChainStep{
+		{Source: batch, Role: RoleCheck},
+		{Source: fallback, Role: RoleFallback},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := c.(BatchSource).IsAvailableBatch(context.Background(), []string{"a.com", "b.com", "c.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if br := results[0]; br.Err != nil || br.Status != StatusTaken {
+		t.Errorf("a.com = %s (error %v), want taken", br.Status, br.Err)
+	}
+	if br := results[1]; br.Err != nil || br.Status != StatusAvailable || br.Source != "fallback" {
+		t.Errorf("b.com = %s from %q (error %v), want available from fallback", br.Status, br.Source, br.Err)
+	}
+	if br := results[2]; br.Err == nil {
+		t.Errorf("c.com = %s, want error", br.Status)
+	}
+}
+
+func TestChainKey(t *testing.T) {
+	dns := &fakeSource{name: "dns"}
+	godaddy := &fakeSource{name: "godaddy"}
+
+	a, _ := NewChain([]ChainStep{{Source: dns, Role: RoleFilter}, {Source: godaddy, Role: RoleCheck}})
+	b, _ := NewChain([]ChainStep{{Source: dns, Role: RoleCheck}, {Source: godaddy, Role: RoleFallback}})
+
+	if Key(a) == Key(b) {
+		t.Errorf("Chains with different compositions share the key %s", Key(a))
+	}
+	if !strings.Contains(Key(a), "godaddy") {
+		t.Errorf("Key(%s) doesn't contain the sources of the chain", Key(a))
+	}
+	if Key(dns) != "dns" {
+		t.Errorf("Key(dns) = %s, want dns", Key(dns))
+	}
+}
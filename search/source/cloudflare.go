@@ -0,0 +1,146 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const cloudflareAPIURL = "https://api.cloudflare.com/client/v4"
+
+// CloudflareConfig holds the configuration for the Cloudflare Registrar
+// source
+type CloudflareConfig struct {
+	AccountID string
+	APIToken  string
+	BaseURL   string
+	Enabled   bool
+	LimitConfig
+}
+
+// Cloudflare allows 1200 requests per 5 minutes
+var cloudflareLimits = LimitConfig{
+	RateLimit:  4,
+	RateBurst:  4,
+	MaxRetries: 3,
+	RetryDelay: 1000,
+}
+
+type cloudflareResponse struct {
+	Success bool
+	Errors  []struct {
+		Code    int
+		Message string
+	}
+	Result struct {
+		Available    bool
+		CanRegister  bool `json:"can_register"`
+		SupportedTLD bool `json:"supported_tld"`
+		Fees         struct {
+			RegistrationFee float64 `json:"registration_fee"`
+			RenewalFee      float64 `json:"renewal_fee"`
+		}
+	}
+}
+
+// Cloudflare handles Cloudflare Registrar API requests
+type Cloudflare struct {
+	config  *CloudflareConfig
+	limiter *limiter
+	client  *http.Client
+	baseURL string
+}
+
+func init() {
+	Register(CloudflareSource, func() interface{} { return new(CloudflareConfig) }, func(config interface{}) (Source, error) {
+		return NewCloudflare(config.(*CloudflareConfig)), nil
+	})
+}
+
+// NewCloudflare returns a new Cloudflare instance
+func NewCloudflare(config *CloudflareConfig) Source {
+	cf := new(Cloudflare)
+
+	cf.config = config
+	limits := config.LimitConfig.withDefaults(cloudflareLimits)
+	cf.limiter = newLimiter(limits)
+	cf.client = newHTTPClient(limits.Timeout)
+	cf.baseURL = baseURL(config.BaseURL, cloudflareAPIURL)
+
+	return cf
+}
+
+// Name returns the name of the source
+func (cf *Cloudflare) Name() string {
+	return "cloudflare"
+}
+
+// IsAvailable checks if a domain is available
+func (cf *Cloudflare) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result := Result{Domain: domain}
+
+	endpoint := fmt.Sprintf(
+		"%s/accounts/%s/registrar/domains/%s",
+		cf.baseURL,
+		url.PathEscape(cf.config.AccountID),
+		url.PathEscape(domain),
+	)
+
+	var cfResponse cloudflareResponse
+	err := cf.limiter.do(ctx, func() error {
+		req, err := http.NewRequest("GET", endpoint, nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Authorization", "Bearer "+cf.config.APIToken)
+
+		status, err := doJSON(ctx, cf.client, req, &cfResponse)
+		if err != nil {
+			return err
+		}
+		if !cfResponse.Success {
+			return cfResponse.err(status)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if !cfResponse.Result.SupportedTLD {
+		return result, &Error{Kind: ErrUnsupportedTLD, Message: domain}
+	}
+
+	result.Definitive = true
+	if !cfResponse.Result.Available || !cfResponse.Result.CanRegister {
+		result.Status = StatusTaken
+		return result, nil
+	}
+
+	result.Status = StatusAvailable
+	result.Price = micros(cfResponse.Result.Fees.RegistrationFee)
+	result.RenewalPrice = micros(cfResponse.Result.Fees.RenewalFee)
+	result.Currency = "USD"
+	result.Period = 1
+
+	return result, nil
+}
+
+// err maps a failed request to a source error
+func (r cloudflareResponse) err(status int) error {
+	var message string
+	if len(r.Errors) > 0 {
+		message = r.Errors[0].Message
+	}
+
+	if status >= 400 {
+		return statusError(status, message)
+	}
+	if message != "" {
+		return errors.New(message)
+	}
+
+	return errors.New("Couldn't check domain")
+}
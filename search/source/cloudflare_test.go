@@ -0,0 +1,68 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestCloudflareIsAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`{"success": false, "errors": [{"code": 10000, "message": "Authentication error"}]}`))
+			return
+		}
+
+		switch strings.TrimPrefix(r.URL.Path, "/accounts/account/registrar/domains/") {
+		case "free.com":
+			w.Write([]byte(`{"success": true, "result": {"available": true, "can_register": true, "supported_tld": true, "fees": {"registration_fee": 9.77, "renewal_fee": 9.77}}}`))
+		case "taken.com":
+			w.Write([]byte(`{"success": true, "result": {"available": false, "can_register": false, "supported_tld": true}}`))
+		case "foo.unsupported":
+			w.Write([]byte(`{"success": true, "result": {"available": false, "supported_tld": false}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	newCloudflare := func(token string) Source {
+		return NewCloudflare(&CloudflareConfig{
+			AccountID:   "account",
+			APIToken:    token,
+			BaseURL:     server.URL,
+			LimitConfig: LimitConfig{RateLimit: -1, MaxRetries: -1},
+		})
+	}
+	cf := newCloudflare("token")
+
+	tests := []struct {
+		domain  string
+		status  Status
+		price   int64
+		wantErr bool
+	}{
+		{"free.com", StatusAvailable, 9770000, false},
+		{"taken.com", StatusTaken, 0, false},
+		{"foo.unsupported", StatusUnknown, 0, true},
+	}
+
+	for _, tt := range tests {
+		result, err := cf.IsAvailable(context.Background(), tt.domain)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IsAvailable(%s) error = %v, want error %t", tt.domain, err, tt.wantErr)
+			continue
+		}
+		if result.Status != tt.status || result.Price != tt.price {
+			t.Errorf("IsAvailable(%s) = %s %d, want %s %d", tt.domain, result.Status, result.Price, tt.status, tt.price)
+		}
+	}
+
+	if _, err := newCloudflare("invalid").IsAvailable(context.Background(), "free.com"); !errors.Is(err, ErrAuth) {
+		t.Errorf("IsAvailable() error = %v, want %v", err, ErrAuth)
+	}
+}
@@ -0,0 +1,183 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// Agreement describes how the results of multiple sources relate to each
+// other
+type Agreement string
+
+const (
+	// Agreed means all sources returned the same availability
+	Agreed Agreement = "agree"
+	// Disagreed means the sources returned different availabilities
+	Disagreed Agreement = "disagree"
+	// PartiallyFailed means the sources that returned a result agree but at
+	// least one source failed
+	PartiallyFailed Agreement = "partial"
+)
+
+// Consensus checks domains against multiple sources and cross-checks the
+// results
+type Consensus struct {
+	sources []Source
+}
+
+// NewConsensus returns a new Consensus instance
+func NewConsensus(sources []Source) (Source, error) {
+	if len(sources) < 2 {
+		return nil, errors.New("Consensus requires at least two sources")
+	}
+
+	c := new(Consensus)
+
+	c.sources = sources
+
+	return c, nil
+}
+
+// Name returns the name of the source
+func (c *Consensus) Name() string {
+	names := make([]string, 0, len(c.sources))
+	for _, s := range c.sources {
+		names = append(names, s.Name())
+	}
+
+	return strings.Join(names, "+")
+}
+
+// Key returns a key identifying the sources that are compared
+func (c *Consensus) Key() string {
+	keys := make([]string, 0, len(c.sources))
+	for _, s := range c.sources {
+		keys = append(keys, Key(s))
+	}
+
+	return "consensus(" + strings.Join(keys, ",") + ")"
+}
+
+// IsAvailable checks if a domain is available
+func (c *Consensus) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	results, err := c.IsAvailableBatch(ctx, []string{domain})
+	if err != nil {
+		return Result{Domain: domain}, err
+	}
+
+	return results[0].Result, results[0].Err
+}
+
+// BatchSize returns the maximum number of domains per batch
+func (c *Consensus) BatchSize() int {
+	size := 1
+	for _, s := range c.sources {
+		if _, ok := s.(BatchSource); ok {
+			size = chainBatchSize
+		}
+	}
+
+	return size
+}
+
+// IsAvailableBatch checks the availability of multiple domains against all
+// sources concurrently
+func (c *Consensus) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	sourceResults := make([]map[string]BatchResult, len(c.sources))
+
+	var wg sync.WaitGroup
+	for i, s := range c.sources {
+		wg.Add(1)
+		go func(i int, s Source) {
+			defer wg.Done()
+
+			byDomain := make(map[string]BatchResult, len(domains))
+			for _, br := range CheckBatch(ctx, s, domains) {
+				byDomain[strings.ToLower(br.Domain)] = br
+			}
+			sourceResults[i] = byDomain
+		}(i, s)
+	}
+	wg.Wait()
+
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, 0, len(domains))
+	for _, domain := range domains {
+		brs := make([]BatchResult, 0, len(c.sources))
+		for i, s := range c.sources {
+			br, ok := sourceResults[i][strings.ToLower(domain)]
+			if !ok {
+				br = BatchResult{Err: errors.New("No result returned")}
+			}
+			br.Domain = domain
+			br.Source = s.Name()
+			brs = append(brs, br)
+		}
+
+		br := c.combine(brs)
+		br.Domain = domain
+		results = append(results, br)
+	}
+
+	return results, nil
+}
+
+// combine combines the results of all sources for a domain. If the sources
+// disagree the majority wins, ties are resolved in favour of the domain being
+// unavailable.
+func (c *Consensus) combine(brs []BatchResult) BatchResult {
+	var available, unavailable []Result
+	var err error
+	details := make([]string, 0, len(brs))
+
+	for _, br := range brs {
+		switch {
+		case br.Err != nil:
+			err = br.Err
+			details = append(details, fmt.Sprintf("%s: error", br.Source))
+			continue
+		case br.Status == StatusUnknown:
+			details = append(details, fmt.Sprintf("%s: %s", br.Source, br.Status))
+			continue
+		case br.Available():
+			available = append(available, br.Result)
+		default:
+			unavailable = append(unavailable, br.Result)
+		}
+		details = append(details, fmt.Sprintf("%s: %s", br.Source, br.Status))
+	}
+
+	if len(available) == 0 && len(unavailable) == 0 {
+		if err == nil {
+			err = errors.New("No source returned a result")
+		}
+		return BatchResult{Err: err}
+	}
+
+	var result Result
+	if len(available) > len(unavailable) {
+		result = available[0]
+	} else {
+		result = unavailable[0]
+	}
+
+	switch {
+	case len(available) > 0 && len(unavailable) > 0:
+		result.Agreement = Disagreed
+		result.Definitive = false
+	case len(available)+len(unavailable) < len(brs):
+		result.Agreement = PartiallyFailed
+	default:
+		result.Agreement = Agreed
+	}
+	result.Message = strings.Join(details, ", ")
+	result.Source = c.Name()
+
+	return BatchResult{Result: result}
+}
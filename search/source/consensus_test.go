@@ -0,0 +1,82 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestConsensusCombine(t *testing.T) {
+	c := &Consensus{sources: []Source{&fakeSource{name: "a"}, &fakeSource{name: "b"}, &fakeSource{name: "c"}}}
+
+	available := BatchResult{Result: Result{Status: StatusAvailable, Definitive: true}}
+	taken := BatchResult{Result: Result{Status: StatusTaken, Definitive: true}}
+	unknown := BatchResult{Result: Result{Status: StatusUnknown}}
+	failed := BatchResult{Err: errors.New("Failed")}
+
+	tests := []struct {
+		name      string
+		results   []BatchResult
+		status    Status
+		agreement Agreement
+		err       bool
+	}{
+		{"all available", []BatchResult{available, available, available}, StatusAvailable, Agreed, false},
+		{"all taken", []BatchResult{taken, taken, taken}, StatusTaken, Agreed, false},
+		{"majority available", []BatchResult{available, taken, available}, StatusAvailable, Disagreed, false},
+		{"majority taken", []BatchResult{available, taken, taken}, StatusTaken, Disagreed, false},
+		{"tie", []BatchResult{available, taken, failed}, StatusTaken, Disagreed, false},
+		{"partially failed", []BatchResult{available, failed, available}, StatusAvailable, PartiallyFailed, false},
+		{"unknown status", []BatchResult{unknown, taken, taken}, StatusTaken, PartiallyFailed, false},
+		{"all failed", []BatchResult{failed, failed, unknown}, StatusUnknown, "", true},
+	}
+
+	for _, tt := range tests {
+		brs := make([]BatchResult, len(tt.results))
+		for i, br := range tt.results {
+			br.Source = c.sources[i].Name()
+			brs[i] = br
+		}
+
+		got := c.combine(brs)
+		if (got.Err != nil) != tt.err {
+			t.Errorf("%s: combine() error = %v, want error %v", tt.name, got.Err, tt.err)
+			continue
+		}
+		if got.Status != tt.status || got.Agreement != tt.agreement {
+			t.Errorf("%s: combine() = %s (%s), want %s (%s)", tt.name, got.Status, got.Agreement, tt.status, tt.agreement)
+		}
+		if got.Agreement == Disagreed && got.Definitive {
+			t.Errorf("%s: combine() disagreeing result is definitive", tt.name)
+		}
+		if !tt.err && got.Source != c.Name() {
+			t.Errorf("%s: combine() source = %s, want %s", tt.name, got.Source, c.Name())
+		}
+	}
+}
+
+func TestConsensusIsAvailableBatch(t *testing.T) {
+	a := &fakeSource{name: "a", statuses: map[string]Status{"free.com": StatusAvailable, "taken.com": StatusTaken}}
+	b := &fakeBatchSource{fakeSource{name: "b", statuses: map[string]Status{"free.com": StatusAvailable}}}
+
+	c, err := NewConsensus([]Source{a, b})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := c.(BatchSource).IsAvailableBatch(context.Background(), []string{"free.com", "taken.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if br := results[0]; br.Err != nil || br.Status != StatusAvailable || br.Agreement != Agreed {
+		t.Errorf("free.com = %s (%s, error %v), want available (%s)", br.Status, br.Agreement, br.Err, Agreed)
+	}
+	// b leaves taken.com out of its response
+	if br := results[1]; br.Err != nil || br.Status != StatusTaken || br.Agreement != PartiallyFailed {
+		t.Errorf("taken.com = %s (%s, error %v), want taken (%s)", br.Status, br.Agreement, br.Err, PartiallyFailed)
+	}
+	if Key(c) != "consensus(a,b)" {
+		t.Errorf("Key() = %s, want consensus(a,b)", Key(c))
+	}
+}
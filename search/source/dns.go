@@ -1,33 +1,153 @@
 package source
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
 	"github.com/domainr/dnsr"
+	mdns "github.com/miekg/dns"
+)
+
+const (
+	dnsDefaultTimeout = 5
+	dnsPort           = "53"
 )
 
+var dnsDefaultRecordTypes = []string{"TXT"}
+
 // DNSConfig holds the configuration for the DNS source
 type DNSConfig struct {
-	Enabled bool
+	Enabled     bool
+	CacheSize   int      // Number of records cached by the iterative resolver
+	Timeout     int      // Timeout per query in seconds
+	RecordTypes []string // Record types to probe (i.e. NS, SOA, TXT)
+	Resolvers   []string // Upstream resolvers to use instead of iterative resolution
 }
 
 // DNS handles checking availablility of domain names via DNS
 type DNS struct {
-	config   *DNSConfig
-	resolver *dnsr.Resolver
+	config      *DNSConfig
+	resolver    *dnsr.Resolver
+	client      *mdns.Client
+	recordTypes []string
+	resolvers   []string
+}
+
+func init() {
+	Register(DNSSource, func() interface{} { return new(DNSConfig) }, func(config interface{}) (Source, error) {
+		return NewDNS(config.(*DNSConfig))
+	})
 }
 
 // NewDNS returns a new DNS instance
-func NewDNS(config *DNSConfig) Source {
-	return &DNS{
-		config:   config,
-		resolver: dnsr.New(0),
+func NewDNS(config *DNSConfig) (Source, error) {
+	dns := new(DNS)
+
+	dns.config = config
+
+	timeout := config.Timeout
+	if timeout <= 0 {
+		timeout = dnsDefaultTimeout
+	}
+
+	dns.recordTypes = dnsDefaultRecordTypes
+	if len(config.RecordTypes) > 0 {
+		dns.recordTypes = make([]string, 0, len(config.RecordTypes))
+		for _, rrType := range config.RecordTypes {
+			rrType = strings.ToUpper(rrType)
+			if _, ok := mdns.StringToType[rrType]; !ok {
+				return nil, fmt.Errorf("Invalid DNS record type: %s", rrType)
+			}
+			dns.recordTypes = append(dns.recordTypes, rrType)
+		}
+	}
+
+	if len(config.Resolvers) > 0 {
+		for _, resolver := range config.Resolvers {
+			if _, _, err := net.SplitHostPort(resolver); err != nil {
+				resolver = net.JoinHostPort(resolver, dnsPort)
+			}
+			dns.resolvers = append(dns.resolvers, resolver)
+		}
+		dns.client = &mdns.Client{Timeout: time.Duration(timeout) * time.Second}
+	} else {
+		dns.resolver = dnsr.NewWithTimeout(config.CacheSize, time.Duration(timeout)*time.Second)
+	}
+
+	return dns, nil
+}
+
+// Name returns the name of the source
+func (dns *DNS) Name() string {
+	return "dns"
+}
+
+// IsAvailable checks if a domain is available. NXDOMAIN is reported as
+// available, NOERROR as taken. Failed lookups (i.e. SERVFAIL or timeouts)
+// are reported as errors.
+func (dns *DNS) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result := Result{Domain: domain}
+
+	var lastErr error
+	for _, rrType := range dns.recordTypes {
+		var err error
+		if dns.client != nil {
+			err = dns.query(ctx, domain, rrType)
+		} else {
+			_, err = dns.resolver.ResolveCtx(ctx, domain, rrType)
+		}
+		if ctx.Err() != nil {
+			return result, ctx.Err()
+		}
+
+		switch err {
+		case nil:
+			result.Status = StatusTaken
+			return result, nil
+		case dnsr.NXDOMAIN:
+			result.Status = StatusAvailable
+			return result, nil
+		}
+
+		lastErr = err
 	}
+
+	return result, &Error{Kind: ErrTransient, Message: fmt.Sprintf("DNS lookup failed: %s", lastErr)}
 }
 
-// IsAvailable checks if a domain is available
-func (dns *DNS) IsAvailable(domain string) (_ bool, err error) {
-	_, err = dns.resolver.ResolveErr(domain, "TXT")
-	if err == dnsr.NXDOMAIN {
-		return true, nil
+// query queries the upstream resolvers. It returns nil for NOERROR and
+// dnsr.NXDOMAIN for NXDOMAIN responses. Resolvers are tried in order until
+// one of them answers.
+func (dns *DNS) query(ctx context.Context, domain, rrType string) error {
+	qtype, ok := mdns.StringToType[rrType]
+	if !ok {
+		return fmt.Errorf("Invalid record type: %s", rrType)
 	}
-	return
+
+	msg := new(mdns.Msg)
+	msg.SetQuestion(mdns.Fqdn(domain), qtype)
+
+	err := errors.New("No resolver configured")
+	for _, resolver := range dns.resolvers {
+		var resp *mdns.Msg
+		resp, _, err = dns.client.ExchangeContext(ctx, msg, resolver)
+		if err != nil {
+			continue
+		}
+
+		switch resp.Rcode {
+		case mdns.RcodeSuccess:
+			return nil
+		case mdns.RcodeNameError:
+			return dnsr.NXDOMAIN
+		}
+
+		err = errors.New(mdns.RcodeToString[resp.Rcode])
+	}
+
+	return err
 }
@@ -0,0 +1,84 @@
+package source
+
+import "errors"
+
+// Error kinds returned by sources. Use errors.Is or KindOf to check for them.
+var (
+	ErrAuth           = errors.New("Authentication failed")
+	ErrUnsupportedTLD = errors.New("Unsupported TLD")
+	ErrInvalidDomain  = errors.New("Invalid domain")
+	ErrRateLimited    = errors.New("Rate limited")
+	ErrTransient      = errors.New("Temporary failure")
+	ErrQuotaExhausted = errors.New("Quota exhausted")
+)
+
+// ErrorKinds lists all error kinds ordered by severity
+var ErrorKinds = []error{
+	ErrAuth,
+	ErrQuotaExhausted,
+	ErrRateLimited,
+	ErrTransient,
+	ErrUnsupportedTLD,
+	ErrInvalidDomain,
+}
+
+// Names of the error kinds as used by the exec source
+var errorKindNames = map[error]string{
+	ErrAuth:           "auth",
+	ErrUnsupportedTLD: "unsupported_tld",
+	ErrInvalidDomain:  "invalid_domain",
+	ErrRateLimited:    "rate_limited",
+	ErrTransient:      "transient",
+	ErrQuotaExhausted: "quota_exhausted",
+}
+
+// Error is a source error of a known kind with details from the API
+type Error struct {
+	Kind    error
+	Message string
+}
+
+// Error returns the error message
+func (e *Error) Error() string {
+	if e.Message == "" {
+		return e.Kind.Error()
+	}
+
+	return e.Kind.Error() + ": " + e.Message
+}
+
+// Unwrap returns the error kind
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+// KindOf returns the kind of an error or nil if it isn't of a known kind
+func KindOf(err error) error {
+	for _, kind := range ErrorKinds {
+		if errors.Is(err, kind) {
+			return kind
+		}
+	}
+
+	return nil
+}
+
+// IsPermanent returns true if retrying a check that failed with err won't
+// change the outcome
+func IsPermanent(err error) bool {
+	return errors.Is(err, ErrAuth) ||
+		errors.Is(err, ErrQuotaExhausted) ||
+		errors.Is(err, ErrUnsupportedTLD) ||
+		errors.Is(err, ErrInvalidDomain)
+}
+
+// parseKind returns the error kind for a name or nil if the name is unknown
+func parseKind(name string) error {
+	for kind, n := range errorKindNames {
+		if n == name {
+			return kind
+		}
+	}
+
+	return nil
+}
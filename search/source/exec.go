@@ -0,0 +1,214 @@
+package source
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// ExecConfig holds the configuration for the external command source
+type ExecConfig struct {
+	Command   string
+	Args      []string
+	Batch     bool // Send all domains of a batch over stdin instead of one run per domain
+	BatchSize int  // Maximum number of domains per run in batch mode
+	Timeout   int  // Timeout per run in seconds
+	Enabled   bool
+}
+
+const (
+	execTimeout   = 30
+	execBatchSize = 50
+)
+
+// execResponse is the answer the command prints for a domain as a single line
+// of JSON. Prices are in units of Currency.
+type execResponse struct {
+	Domain       string  `json:"domain"`
+	Status       string  `json:"status"`
+	Price        float64 `json:"price"`
+	RenewalPrice float64 `json:"renewal_price"`
+	Period       int     `json:"period"`
+	Currency     string  `json:"currency"`
+	Definitive   bool    `json:"definitive"`
+	Message      string  `json:"message"`
+	Error        string  `json:"error"`
+	ErrorKind    string  `json:"error_kind"`
+}
+
+// Exec runs an external command to check domains. In single mode the command
+// is run once per domain with the domain as last argument. In batch mode the
+// domains are written to stdin one per line. Either way the command prints one
+// JSON object per domain and line to stdout.
+type Exec struct {
+	config *ExecConfig
+}
+
+// execBatch is an Exec running in batch mode
+type execBatch struct {
+	*Exec
+}
+
+func init() {
+	Register(ExecSource, func() interface{} { return new(ExecConfig) }, func(config interface{}) (Source, error) {
+		return NewExec(config.(*ExecConfig))
+	})
+}
+
+// NewExec returns a new Exec instance
+func NewExec(config *ExecConfig) (Source, error) {
+	if config.Command == "" {
+		return nil, errors.New("No command configured")
+	}
+
+	e := &Exec{config: config}
+
+	if config.Batch {
+		return &execBatch{e}, nil
+	}
+
+	return e, nil
+}
+
+// Name returns the name of the source
+func (e *Exec) Name() string {
+	return "exec"
+}
+
+// IsAvailable checks if a domain is available
+func (e *Exec) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	args := append(append([]string{}, e.config.Args...), domain)
+
+	results, err := e.run(ctx, args, nil)
+	if err != nil {
+		return Result{Domain: domain}, err
+	}
+	if len(results) == 0 {
+		return Result{Domain: domain}, errors.New("Empty command response")
+	}
+
+	r := results[0]
+	if r.Domain == "" {
+		r.Domain = domain
+	}
+
+	return r.Result, r.Err
+}
+
+// BatchSize returns the maximum number of domains per run
+func (e *execBatch) BatchSize() int {
+	if e.config.BatchSize > 0 {
+		return e.config.BatchSize
+	}
+
+	return execBatchSize
+}
+
+// IsAvailable checks if a domain is available
+func (e *execBatch) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	results, err := e.IsAvailableBatch(ctx, []string{domain})
+	if err != nil {
+		return Result{Domain: domain}, err
+	}
+	if len(results) == 0 {
+		return Result{Domain: domain}, errors.New("Empty command response")
+	}
+
+	return results[0].Result, results[0].Err
+}
+
+// IsAvailableBatch checks the availability of multiple domains with a single
+// run of the command
+func (e *execBatch) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	stdin := strings.Join(domains, "\n") + "\n"
+
+	return e.run(ctx, e.config.Args, strings.NewReader(stdin))
+}
+
+// run runs the command and parses its output. The command is killed when ctx
+// is done.
+func (e *Exec) run(parent context.Context, args []string, stdin *strings.Reader) ([]BatchResult, error) {
+	timeout := e.config.Timeout
+	if timeout <= 0 {
+		timeout = execTimeout
+	}
+	ctx, cancel := context.WithTimeout(parent, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, e.config.Command, args...)
+	if stdin != nil {
+		cmd.Stdin = stdin
+	}
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if parent.Err() != nil {
+			return nil, parent.Err()
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, &retryableError{err: &Error{Kind: ErrTransient, Message: "Command timed out"}}
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("Command failed: %s", msg)
+		}
+		return nil, fmt.Errorf("Command failed: %s", err)
+	}
+
+	var results []BatchResult
+	scanner := bufio.NewScanner(&stdout)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+
+		var r execResponse
+		if err := json.Unmarshal([]byte(line), &r); err != nil {
+			return nil, errors.New("Couldn't parse command response")
+		}
+
+		results = append(results, r.result())
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.New("Couldn't read command response")
+	}
+
+	return results, nil
+}
+
+// result converts a command response into a BatchResult
+func (r execResponse) result() BatchResult {
+	br := BatchResult{Result: Result{Domain: r.Domain}}
+
+	if r.Error != "" {
+		if kind := parseKind(r.ErrorKind); kind != nil {
+			br.Err = &Error{Kind: kind, Message: r.Error}
+		} else {
+			br.Err = errors.New(r.Error)
+		}
+		return br
+	}
+
+	br.Status = ParseStatus(r.Status)
+	if br.Status == StatusUnknown {
+		br.Err = fmt.Errorf("Unknown status: %s", r.Status)
+		return br
+	}
+
+	br.Price = micros(r.Price)
+	br.RenewalPrice = micros(r.RenewalPrice)
+	br.Period = r.Period
+	br.Currency = r.Currency
+	br.Definitive = r.Definitive
+	br.Message = r.Message
+
+	return br
+}
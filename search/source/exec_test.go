@@ -0,0 +1,94 @@
+package source
+
+import (
+	"context"
+	"testing"
+)
+
+// execScript answers the domain in $d with a JSON line
+const execScript = `
+case "$d" in
+free.*) echo "{\"domain\": \"$d\", \"status\": \"available\", \"price\": 9.99, \"currency\": \"USD\", \"period\": 1}" ;;
+fail.*) echo "{\"domain\": \"$d\", \"error\": \"Lookup failed\"}" ;;
+crash.*) echo "Crashed" >&2; exit 1 ;;
+*) echo "{\"domain\": \"$d\", \"status\": \"taken\", \"definitive\": true}" ;;
+esac
+`
+
+func TestExecIsAvailable(t *testing.T) {
+	e, err := NewExec(&ExecConfig{
+		Command: "sh",
+		Args:    []string{"-c", `d="$1"` + execScript, "sh"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := e.(BatchSource); ok {
+		t.Fatal("Exec in single mode is a batch source")
+	}
+
+	tests := []struct {
+		domain  string
+		status  Status
+		price   int64
+		wantErr string
+	}{
+		{"free.com", StatusAvailable, 9990000, ""},
+		{"taken.com", StatusTaken, 0, ""},
+		{"fail.com", StatusUnknown, 0, "Lookup failed"},
+		{"crash.com", StatusUnknown, 0, "Command failed: Crashed"},
+	}
+
+	for _, tt := range tests {
+		result, err := e.IsAvailable(context.Background(), tt.domain)
+		if (err == nil && tt.wantErr != "") || (err != nil && err.Error() != tt.wantErr) {
+			t.Errorf("IsAvailable(%s) error = %v, want %q", tt.domain, err, tt.wantErr)
+			continue
+		}
+		if result.Status != tt.status || result.Price != tt.price {
+			t.Errorf("IsAvailable(%s) = %s %d, want %s %d", tt.domain, result.Status, result.Price, tt.status, tt.price)
+		}
+	}
+}
+
+func TestExecIsAvailableBatch(t *testing.T) {
+	e, err := NewExec(&ExecConfig{
+		Command:   "sh",
+		Args:      []string{"-c", `while read -r d; do` + execScript + `done`},
+		Batch:     true,
+		BatchSize: 2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bs, ok := e.(BatchSource)
+	if !ok {
+		t.Fatal("Exec in batch mode isn't a batch source")
+	}
+	if bs.BatchSize() != 2 {
+		t.Errorf("BatchSize() = %d, want 2", bs.BatchSize())
+	}
+
+	results, err := bs.IsAvailableBatch(context.Background(), []string{"free.com", "taken.com", "fail.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 3 {
+		t.Fatalf("IsAvailableBatch() returned %d results, want 3", len(results))
+	}
+
+	if r := results[0]; r.Err != nil || r.Domain != "free.com" || r.Status != StatusAvailable || r.Currency != "USD" {
+		t.Errorf("free.com = %+v, want available in USD", r)
+	}
+	if r := results[1]; r.Err != nil || r.Domain != "taken.com" || r.Status != StatusTaken || !r.Definitive {
+		t.Errorf("taken.com = %+v, want definitive taken", r)
+	}
+	if r := results[2]; r.Err == nil || r.Domain != "fail.com" {
+		t.Errorf("fail.com = %+v, want error", r)
+	}
+
+	if _, err := bs.IsAvailableBatch(context.Background(), []string{"crash.com"}); err == nil {
+		t.Error("IsAvailableBatch() error = nil, want error")
+	}
+}
@@ -0,0 +1,135 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+const gandiAPIURL = "https://api.gandi.net/v5"
+
+// GandiConfig holds the configuration for the gandi.net source
+type GandiConfig struct {
+	APIKey  string // Personal access token
+	BaseURL string
+	Enabled bool
+	LimitConfig
+}
+
+// Gandi allows 1000 requests per minute
+var gandiLimits = LimitConfig{
+	RateLimit:  10,
+	RateBurst:  10,
+	MaxRetries: 3,
+	RetryDelay: 1000,
+}
+
+type gandiResponse struct {
+	Currency string
+	Message  string
+	Products []struct {
+		Status  string
+		Name    string
+		Process string
+		Prices  []struct {
+			PriceBeforeTaxes float64 `json:"price_before_taxes"`
+			DurationUnit     string  `json:"duration_unit"`
+			MinDuration      int     `json:"min_duration"`
+		}
+	}
+}
+
+// Gandi handles gandi.net API requests
+type Gandi struct {
+	config  *GandiConfig
+	limiter *limiter
+	client  *http.Client
+	baseURL string
+}
+
+func init() {
+	Register(GandiSource, func() interface{} { return new(GandiConfig) }, func(config interface{}) (Source, error) {
+		return NewGandi(config.(*GandiConfig)), nil
+	})
+}
+
+// NewGandi returns a new Gandi instance
+func NewGandi(config *GandiConfig) Source {
+	g := new(Gandi)
+
+	g.config = config
+	limits := config.LimitConfig.withDefaults(gandiLimits)
+	g.limiter = newLimiter(limits)
+	g.client = newHTTPClient(limits.Timeout)
+	g.baseURL = baseURL(config.BaseURL, gandiAPIURL)
+
+	return g
+}
+
+// Name returns the name of the source
+func (g *Gandi) Name() string {
+	return "gandi"
+}
+
+// IsAvailable checks if a domain is available
+func (g *Gandi) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result := Result{Domain: domain}
+
+	v := url.Values{}
+	v.Set("name", domain)
+
+	var gResponse gandiResponse
+	err := g.limiter.do(ctx, func() error {
+		req, err := http.NewRequest("GET", g.baseURL+"/domain/check?"+v.Encode(), nil)
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Authorization", "Bearer "+g.config.APIKey)
+
+		status, err := doJSON(ctx, g.client, req, &gResponse)
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK {
+			return statusError(status, gResponse.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	// Gandi doesn't return products for TLDs it doesn't sell
+	if len(gResponse.Products) == 0 {
+		return result, &Error{Kind: ErrUnsupportedTLD, Message: domain}
+	}
+
+	product := gResponse.Products[0]
+	switch product.Status {
+	case "available":
+		result.Status = StatusAvailable
+	case "unavailable", "pending":
+		result.Status = StatusTaken
+	case "reserved":
+		result.Status = StatusReserved
+	case "error_invalid":
+		return result, &Error{Kind: ErrInvalidDomain, Message: domain}
+	case "error_timeout":
+		return result, &Error{Kind: ErrTransient, Message: "Registry timeout"}
+	default:
+		return result, fmt.Errorf("Couldn't check domain: %s", product.Status)
+	}
+	result.Definitive = true
+
+	for _, price := range product.Prices {
+		if price.DurationUnit == "y" && price.MinDuration <= 1 {
+			result.Price = micros(price.PriceBeforeTaxes)
+			result.Currency = gResponse.Currency
+			result.Period = 1
+			break
+		}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,77 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGandiIsAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/domain/check" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte(`{"message": "Invalid token"}`))
+			return
+		}
+
+		switch r.URL.Query().Get("name") {
+		case "free.com":
+			w.Write([]byte(`{"currency": "EUR", "products": [{"status": "available", "name": "free.com", "prices": [
+				{"price_before_taxes": 24.5, "duration_unit": "y", "min_duration": 2},
+				{"price_before_taxes": 12.5, "duration_unit": "y", "min_duration": 1}
+			]}]}`))
+		case "taken.com":
+			w.Write([]byte(`{"currency": "EUR", "products": [{"status": "unavailable", "name": "taken.com"}]}`))
+		case "reserved.com":
+			w.Write([]byte(`{"currency": "EUR", "products": [{"status": "reserved", "name": "reserved.com"}]}`))
+		default:
+			w.Write([]byte(`{"currency": "EUR", "products": []}`))
+		}
+	}))
+	defer server.Close()
+
+	newGandi := func(key string) Source {
+		return NewGandi(&GandiConfig{
+			APIKey:      key,
+			BaseURL:     server.URL,
+			LimitConfig: LimitConfig{RateLimit: -1, MaxRetries: -1},
+		})
+	}
+	g := newGandi("token")
+
+	tests := []struct {
+		domain  string
+		status  Status
+		price   int64
+		wantErr bool
+	}{
+		{"free.com", StatusAvailable, 12500000, false},
+		{"taken.com", StatusTaken, 0, false},
+		{"reserved.com", StatusReserved, 0, false},
+		{"empty.com", StatusUnknown, 0, true},
+	}
+
+	for _, tt := range tests {
+		result, err := g.IsAvailable(context.Background(), tt.domain)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IsAvailable(%s) error = %v, want error %t", tt.domain, err, tt.wantErr)
+			continue
+		}
+		if result.Status != tt.status || result.Price != tt.price {
+			t.Errorf("IsAvailable(%s) = %s %d, want %s %d", tt.domain, result.Status, result.Price, tt.status, tt.price)
+		}
+		if result.Price > 0 && result.Currency != "EUR" {
+			t.Errorf("IsAvailable(%s) currency = %s, want EUR", tt.domain, result.Currency)
+		}
+	}
+
+	if _, err := newGandi("invalid").IsAvailable(context.Background(), "free.com"); !errors.Is(err, ErrAuth) {
+		t.Errorf("IsAvailable() error = %v, want %v", err, ErrAuth)
+	}
+}
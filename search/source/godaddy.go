@@ -1,19 +1,46 @@
 package source
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"strings"
+	"time"
 )
 
-// GoDaddyConfig holds the configuration for the namecheap.com source
+// GoDaddyConfig holds the configuration for the godaddy.com source
 type GoDaddyConfig struct {
-	Key     string
-	Secret  string
-	Enabled bool
+	Key          string
+	Secret       string
+	Enabled      bool
+	PremiumPrice float64 // Available domains above this price are reported as premium
+	Sandbox      bool    // Use the OTE (test) environment
+	BaseURL      string  // Overrides the API endpoint (i.e. for a local stand-in)
+	LimitConfig
+}
+
+const (
+	goDaddyAPIURL     = "https://api.godaddy.com"
+	goDaddySandboxURL = "https://api.ote-godaddy.com"
+)
+
+// Default price above which domains are considered premium
+const goDaddyPremiumPrice = 100
+
+// Maximum number of domains per bulk request
+const goDaddyBatchSize = 500
+
+// GoDaddy allows 60 availability requests per minute
+var goDaddyLimits = LimitConfig{
+	RateLimit:  1,
+	RateBurst:  1,
+	MaxRetries: 3,
+	RetryDelay: 1000,
 }
 
 type goDaddyResponse struct {
@@ -26,12 +53,34 @@ type goDaddyResponse struct {
 	Message    string
 	Code       string
 	Name       string
+	Status     int // HTTP status of per domain errors of bulk requests
+}
+
+type goDaddyBulkResponse struct {
+	Domains []goDaddyResponse
+	Errors  []goDaddyResponse
+}
+
+// goDaddyError is the body of failed requests
+type goDaddyError struct {
+	Code          string
+	Message       string
+	RetryAfterSec int
 }
 
 // GoDaddy handles godaddy.com API requests
 type GoDaddy struct {
 	Source
-	config *GoDaddyConfig
+	config  *GoDaddyConfig
+	limiter *limiter
+	client  *http.Client
+	baseURL string
+}
+
+func init() {
+	Register(GoDaddySource, func() interface{} { return new(GoDaddyConfig) }, func(config interface{}) (Source, error) {
+		return NewGoDaddy(config.(*GoDaddyConfig)), nil
+	})
 }
 
 // NewGoDaddy returns a new GoDaddy instance
@@ -39,36 +88,175 @@ func NewGoDaddy(config *GoDaddyConfig) Source {
 	gd := new(GoDaddy)
 
 	gd.config = config
+	limits := config.LimitConfig.withDefaults(goDaddyLimits)
+	gd.limiter = newLimiter(limits)
+	gd.client = newHTTPClient(limits.Timeout)
+
+	gd.baseURL = goDaddyAPIURL
+	if config.Sandbox {
+		gd.baseURL = goDaddySandboxURL
+	}
+	gd.baseURL = baseURL(config.BaseURL, gd.baseURL)
 
 	return gd
 }
 
-// IsAvailable checks if a domain is available
-func (gd *GoDaddy) IsAvailable(domain string) (bool, error) {
-	client := &http.Client{}
+// Name returns the name of the source
+func (gd *GoDaddy) Name() string {
+	return "godaddy"
+}
 
+// IsAvailable checks if a domain is available
+func (gd *GoDaddy) IsAvailable(ctx context.Context, domain string) (Result, error) {
 	v := url.Values{}
 	v.Set("domain", domain)
-	req, err := http.NewRequest("GET", "https://api.godaddy.com/v1/domains/available?"+v.Encode(), nil)
+
+	var gdResponse goDaddyResponse
+	err := gd.limiter.do(ctx, func() error {
+		req, err := http.NewRequest("GET", gd.baseURL+"/v1/domains/available?"+v.Encode(), nil)
+		if err != nil {
+			return err
+		}
+
+		return gd.request(ctx, req, &gdResponse)
+	})
+	if err != nil {
+		return Result{Domain: domain}, err
+	}
+
+	return gdResponse.result(domain, gd.premiumPrice()), nil
+}
+
+// BatchSize returns the maximum number of domains per bulk request
+func (gd *GoDaddy) BatchSize() int {
+	return goDaddyBatchSize
+}
+
+// IsAvailableBatch checks the availability of multiple domains with a single
+// request
+func (gd *GoDaddy) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	body, err := json.Marshal(domains)
+	if err != nil {
+		return nil, err
+	}
+
+	var gdResponse goDaddyBulkResponse
+	err = gd.limiter.do(ctx, func() error {
+		req, err := http.NewRequest("POST", gd.baseURL+"/v1/domains/available?checkType=FAST", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Content-Type", "application/json")
+
+		return gd.request(ctx, req, &gdResponse)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, 0, len(domains))
+	for _, r := range gdResponse.Domains {
+		results = append(results, BatchResult{Result: r.result(r.Domain, gd.premiumPrice())})
+	}
+	for _, r := range gdResponse.Errors {
+		results = append(results, BatchResult{
+			Result: Result{Domain: r.Domain},
+			Err:    goDaddyStatusError(r.Status, r.Code, r.Message),
+		})
+	}
+
+	return results, nil
+}
+
+// request performs an API request and decodes the response into out. Failed
+// requests are mapped to source errors.
+func (gd *GoDaddy) request(ctx context.Context, req *http.Request, out interface{}) error {
 	req.Header.Add("Authorization", fmt.Sprintf("sso-key %s:%s", gd.config.Key, gd.config.Secret))
-	resp, err := client.Do(req)
+	req.Header.Add("Accept", "application/json")
+
+	resp, err := send(ctx, gd.client, req)
 	if err != nil {
-		return false, errors.New("Couldn't connect to API")
+		return err
 	}
+	defer resp.Body.Close()
 
 	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		return false, errors.New("Couldn't read API response")
+		return &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't read API response"}}
 	}
 
-	var gdResponse goDaddyResponse
-	if err := json.Unmarshal(body, &gdResponse); err != nil {
-		return false, errors.New("Couldn't parse API response")
+	// Bulk requests with per domain errors return 203
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		// The error body is optional
+		var gdError goDaddyError
+		json.Unmarshal(body, &gdError)
+
+		err := withRetryAfter(goDaddyStatusError(resp.StatusCode, gdError.Code, gdError.Message), resp)
+
+		var re *retryableError
+		if errors.As(err, &re) && re.retryAfter == 0 {
+			re.retryAfter = time.Duration(gdError.RetryAfterSec) * time.Second
+		}
+
+		return err
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return errors.New("Couldn't parse API response")
+	}
+
+	return nil
+}
+
+// goDaddyStatusError maps the HTTP status and error code of a failed request
+// to a source error
+func goDaddyStatusError(status int, code, message string) error {
+	if message == "" {
+		message = code
+	}
+
+	switch {
+	case strings.Contains(code, "QUOTA"):
+		return &Error{Kind: ErrQuotaExhausted, Message: message}
+	case status == http.StatusUnprocessableEntity && strings.Contains(code, "TLD"):
+		return &Error{Kind: ErrUnsupportedTLD, Message: message}
+	case status == http.StatusUnprocessableEntity:
+		return &Error{Kind: ErrInvalidDomain, Message: message}
+	}
+
+	return statusError(status, message)
+}
+
+// premiumPrice returns the price in micro-units above which domains are
+// considered premium
+func (gd *GoDaddy) premiumPrice() int64 {
+	price := gd.config.PremiumPrice
+	if price <= 0 {
+		price = goDaddyPremiumPrice
+	}
+
+	return int64(price * 1000000)
+}
+
+// result converts an API response into a Result. The API doesn't flag premium
+// domains so available domains above premiumPrice are reported as premium.
+func (r goDaddyResponse) result(domain string, premiumPrice int64) Result {
+	result := Result{
+		Domain:     domain,
+		Definitive: r.Definitive,
 	}
 
-	if gdResponse.Message != "" {
-		return false, errors.New(gdResponse.Message)
+	if r.Available {
+		result.Status = StatusAvailable
+		result.Price = int64(r.Price)
+		result.Currency = r.Currency
+		result.Period = r.Period
+		if result.Price > premiumPrice {
+			result.Status = StatusPremium
+		}
+	} else {
+		result.Status = StatusTaken
 	}
 
-	return gdResponse.Available, nil
+	return result
 }
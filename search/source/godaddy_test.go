@@ -0,0 +1,160 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestGoDaddyStatusError(t *testing.T) {
+	tests := []struct {
+		status int
+		code   string
+		kind   error
+	}{
+		{401, "UNABLE_TO_AUTHENTICATE", ErrAuth},
+		{403, "ACCESS_DENIED", ErrAuth},
+		{422, "UNSUPPORTED_TLD", ErrUnsupportedTLD},
+		{422, "INVALID_DOMAIN", ErrInvalidDomain},
+		{429, "TOO_MANY_REQUESTS", ErrRateLimited},
+		{429, "QUOTA_EXCEEDED", ErrQuotaExhausted},
+		{500, "INTERNAL_SERVER_ERROR", ErrTransient},
+		{400, "BAD_REQUEST", nil},
+	}
+
+	for _, tt := range tests {
+		err := goDaddyStatusError(tt.status, tt.code, "")
+		if err == nil {
+			t.Errorf("goDaddyStatusError(%d, %s) = nil, want error", tt.status, tt.code)
+			continue
+		}
+		if got := KindOf(err); got != tt.kind {
+			t.Errorf("goDaddyStatusError(%d, %s) kind = %v, want %v", tt.status, tt.code, got, tt.kind)
+		}
+	}
+}
+
+// newGoDaddyStandIn returns a GoDaddy source talking to a local stand-in of
+// the API
+func newGoDaddyStandIn(t *testing.T, handler http.HandlerFunc) Source {
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewGoDaddy(&GoDaddyConfig{
+		Key:         "key",
+		Secret:      "secret",
+		BaseURL:     server.URL,
+		LimitConfig: LimitConfig{RateLimit: -1, MaxRetries: -1},
+	})
+}
+
+func TestGoDaddyIsAvailable(t *testing.T) {
+	gd := newGoDaddyStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v1/domains/available" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "sso-key key:secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		domain := r.URL.Query().Get("domain")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"available":  domain != "taken.com",
+			"domain":     domain,
+			"definitive": true,
+			"price":      map[string]int{"foo.com": 11990000, "premium.com": 2500000000}[domain],
+			"currency":   "USD",
+			"period":     1,
+		})
+	})
+
+	tests := []struct {
+		domain string
+		status Status
+		price  int64
+	}{
+		{"foo.com", StatusAvailable, 11990000},
+		{"taken.com", StatusTaken, 0},
+		{"premium.com", StatusPremium, 2500000000},
+	}
+
+	for _, tt := range tests {
+		result, err := gd.IsAvailable(context.Background(), tt.domain)
+		if err != nil {
+			t.Errorf("IsAvailable(%s) error = %v", tt.domain, err)
+			continue
+		}
+		if result.Status != tt.status || result.Price != tt.price || !result.Definitive {
+			t.Errorf("IsAvailable(%s) = %+v, want status %s and price %d", tt.domain, result, tt.status, tt.price)
+		}
+	}
+}
+
+func TestGoDaddyErrors(t *testing.T) {
+	gd := newGoDaddyStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Query().Get("domain") {
+		case "foo.unsupported":
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(map[string]string{"code": "UNSUPPORTED_TLD", "message": "TLD not supported"})
+		case "quota.com":
+			w.WriteHeader(http.StatusTooManyRequests)
+			json.NewEncoder(w).Encode(map[string]string{"code": "QUOTA_EXCEEDED"})
+		default:
+			w.WriteHeader(http.StatusUnauthorized)
+		}
+	})
+
+	tests := []struct {
+		domain string
+		kind   error
+	}{
+		{"foo.unsupported", ErrUnsupportedTLD},
+		{"quota.com", ErrQuotaExhausted},
+		{"foo.com", ErrAuth},
+	}
+
+	for _, tt := range tests {
+		if _, err := gd.IsAvailable(context.Background(), tt.domain); !errors.Is(err, tt.kind) {
+			t.Errorf("IsAvailable(%s) error = %v, want %v", tt.domain, err, tt.kind)
+		}
+	}
+}
+
+func TestGoDaddyIsAvailableBatch(t *testing.T) {
+	gd := newGoDaddyStandIn(t, func(w http.ResponseWriter, r *http.Request) {
+		var domains []string
+		if r.Method != "POST" || json.NewDecoder(r.Body).Decode(&domains) != nil || len(domains) != 2 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusNonAuthoritativeInfo)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"domains": []map[string]interface{}{
+				{"available": true, "domain": domains[0], "price": 9990000, "currency": "USD", "period": 1},
+			},
+			"errors": []map[string]interface{}{
+				{"domain": domains[1], "code": "UNSUPPORTED_TLD", "message": "TLD not supported", "status": 422},
+			},
+		})
+	})
+
+	results, err := gd.(BatchSource).IsAvailableBatch(context.Background(), []string{"foo.com", "foo.unsupported"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("IsAvailableBatch() returned %d results, want 2", len(results))
+	}
+	if results[0].Domain != "foo.com" || results[0].Status != StatusAvailable || results[0].Err != nil {
+		t.Errorf("IsAvailableBatch() result = %+v, want foo.com available", results[0])
+	}
+	if results[1].Domain != "foo.unsupported" || !errors.Is(results[1].Err, ErrUnsupportedTLD) {
+		t.Errorf("IsAvailableBatch() result = %+v, want unsupported TLD error", results[1])
+	}
+}
@@ -0,0 +1,126 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// newHTTPClient returns a client for API requests. Clients share the default
+// transport so connections are reused across requests.
+func newHTTPClient(timeout int) *http.Client {
+	return &http.Client{Timeout: time.Duration(timeout) * time.Second}
+}
+
+// send performs a request bound to ctx. Connection failures are returned as
+// retryable errors unless ctx is done.
+func send(ctx context.Context, client *http.Client, req *http.Request) (*http.Response, error) {
+	resp, err := client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't connect to API"}}
+	}
+
+	return resp, nil
+}
+
+// doJSON performs an API request and decodes the JSON response into out. It
+// returns the HTTP status code. Rate limited requests and server errors are
+// returned as retryable errors. Failed requests without a JSON body are
+// mapped to source errors by their status.
+func doJSON(ctx context.Context, client *http.Client, req *http.Request, out interface{}) (int, error) {
+	resp, err := send(ctx, client, req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return resp.StatusCode, withRetryAfter(statusError(resp.StatusCode, resp.Status), resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return resp.StatusCode, &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't read API response"}}
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		if len(body) == 0 || json.Unmarshal(body, out) != nil {
+			return resp.StatusCode, statusError(resp.StatusCode, resp.Status)
+		}
+		return resp.StatusCode, nil
+	}
+
+	if len(body) > 0 {
+		if err := json.Unmarshal(body, out); err != nil {
+			return resp.StatusCode, errors.New("Couldn't parse API response")
+		}
+	}
+
+	return resp.StatusCode, nil
+}
+
+// statusError maps the HTTP status of a failed API request to a source error.
+// Rate limited requests and server errors are retryable.
+func statusError(status int, message string) error {
+	if message == "" {
+		message = fmt.Sprintf("%d %s", status, http.StatusText(status))
+	}
+
+	switch {
+	case status == http.StatusUnauthorized || status == http.StatusForbidden:
+		return &Error{Kind: ErrAuth, Message: message}
+	case status == http.StatusPaymentRequired:
+		return &Error{Kind: ErrQuotaExhausted, Message: message}
+	case status == http.StatusTooManyRequests:
+		return &retryableError{err: &Error{Kind: ErrRateLimited, Message: message}}
+	case status >= 500:
+		return &retryableError{err: &Error{Kind: ErrTransient, Message: message}}
+	}
+
+	return fmt.Errorf("API request failed: %s", message)
+}
+
+// withRetryAfter sets the delay requested by the Retry-After header of resp
+// on retryable errors
+func withRetryAfter(err error, resp *http.Response) error {
+	var re *retryableError
+	if errors.As(err, &re) {
+		re.retryAfter = retryAfter(resp)
+	}
+
+	return err
+}
+
+// baseURL returns the configured base URL or the default without a trailing
+// slash
+func baseURL(configured, defaultURL string) string {
+	if configured == "" {
+		configured = defaultURL
+	}
+
+	return strings.TrimSuffix(configured, "/")
+}
+
+// micros converts a price to micro-units
+func micros(price float64) int64 {
+	return int64(price * 1000000)
+}
+
+// parseMicros converts a decimal price string to micro-units
+func parseMicros(price string) int64 {
+	f, err := strconv.ParseFloat(price, 64)
+	if err != nil {
+		return 0
+	}
+
+	return micros(f)
+}
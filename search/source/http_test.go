@@ -0,0 +1,118 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestDoJSON(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		body    string
+		wantErr string
+	}{
+		{"ok", http.StatusOK, `{"Message": "ok"}`, ""},
+		{"invalid JSON", http.StatusOK, "<html>", "Couldn't parse API response"},
+		{"error without JSON body", http.StatusUnauthorized, "Unauthorized", "Authentication failed: 401 Unauthorized"},
+		{"error with JSON body", http.StatusNotFound, `{"Message": "Not found"}`, ""},
+		{"error without body", http.StatusNotFound, "", "API request failed: 404 Not Found"},
+		{"no content", http.StatusNoContent, "", ""},
+	}
+
+	for _, tt := range tests {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(tt.status)
+			w.Write([]byte(tt.body))
+		}))
+
+		req, err := http.NewRequest("GET", server.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var out struct{ Message string }
+		status, err := doJSON(context.Background(), server.Client(), req, &out)
+		server.Close()
+
+		if status != tt.status {
+			t.Errorf("%s: doJSON() status = %d, want %d", tt.name, status, tt.status)
+		}
+		if tt.wantErr == "" && err != nil {
+			t.Errorf("%s: doJSON() error = %v, want nil", tt.name, err)
+		}
+		if tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)) {
+			t.Errorf("%s: doJSON() error = %v, want %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestStatusError(t *testing.T) {
+	tests := []struct {
+		status    int
+		kind      error
+		retryable bool
+	}{
+		{400, nil, false},
+		{401, ErrAuth, false},
+		{403, ErrAuth, false},
+		{402, ErrQuotaExhausted, false},
+		{404, nil, false},
+		{429, ErrRateLimited, true},
+		{500, ErrTransient, true},
+		{503, ErrTransient, true},
+	}
+
+	for _, tt := range tests {
+		err := statusError(tt.status, "")
+		if err == nil {
+			t.Errorf("statusError(%d) = nil, want error", tt.status)
+			continue
+		}
+		if got := KindOf(err); got != tt.kind {
+			t.Errorf("statusError(%d) kind = %v, want %v", tt.status, got, tt.kind)
+		}
+		if got := isRetryable(err); got != tt.retryable {
+			t.Errorf("statusError(%d) retryable = %v, want %v", tt.status, got, tt.retryable)
+		}
+	}
+}
+
+func TestStatusErrorMessage(t *testing.T) {
+	if got, want := statusError(401, "").Error(), "Authentication failed: 401 Unauthorized"; got != want {
+		t.Errorf("statusError() = %q, want %q", got, want)
+	}
+	if got, want := statusError(401, "Invalid key").Error(), "Authentication failed: Invalid key"; got != want {
+		t.Errorf("statusError() = %q, want %q", got, want)
+	}
+}
+
+func TestIsRetried(t *testing.T) {
+	l := newLimiter(LimitConfig{RateLimit: -1, MaxRetries: 2, RetryDelay: 1})
+
+	attempts := 0
+	err := l.do(context.Background(), func() error {
+		attempts++
+		return statusError(503, "")
+	})
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+	if !IsRetried(err) || !errors.Is(err, ErrTransient) {
+		t.Errorf("do() error = %v, want retried transient error", err)
+	}
+
+	// Permanent errors aren't retried
+	attempts = 0
+	err = l.do(context.Background(), func() error {
+		attempts++
+		return statusError(401, "")
+	})
+	if attempts != 1 || IsRetried(err) {
+		t.Errorf("attempts = %d, retried = %v, want 1 attempt without retries", attempts, IsRetried(err))
+	}
+}
@@ -0,0 +1,227 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const maxRetryDelay = 30 * time.Second
+
+// Default request timeout in seconds
+const defaultTimeout = 30
+
+// LimitConfig holds the rate limiting, retry and timeout configuration of a
+// source
+type LimitConfig struct {
+	RateLimit  float64 // Requests per second (negative disables rate limiting)
+	RateBurst  int     // Number of requests that can be made at once
+	MaxRetries int     // Retries for rate limited or failed requests (negative disables retries)
+	RetryDelay int     // Initial delay between retries in milliseconds
+	Timeout    int     // Timeout per request in seconds
+}
+
+// withDefaults returns a copy of the config with unset values replaced by the
+// given defaults
+func (lc LimitConfig) withDefaults(defaults LimitConfig) LimitConfig {
+	if lc.RateLimit == 0 {
+		lc.RateLimit = defaults.RateLimit
+	}
+	if lc.RateBurst == 0 {
+		lc.RateBurst = defaults.RateBurst
+	}
+	if lc.MaxRetries == 0 {
+		lc.MaxRetries = defaults.MaxRetries
+	}
+	if lc.RetryDelay == 0 {
+		lc.RetryDelay = defaults.RetryDelay
+	}
+	if lc.Timeout <= 0 {
+		lc.Timeout = defaults.Timeout
+	}
+	if lc.Timeout <= 0 {
+		lc.Timeout = defaultTimeout
+	}
+
+	return lc
+}
+
+// retryableError marks an error as temporary. RetryAfter holds the delay
+// requested by the API if there was one.
+type retryableError struct {
+	err        error
+	retryAfter time.Duration
+}
+
+// Error returns the error message
+func (e *retryableError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *retryableError) Unwrap() error {
+	return e.err
+}
+
+// retriedError is a retryable error the limiter gave up retrying
+type retriedError struct {
+	err error
+}
+
+// Error returns the error message
+func (e *retriedError) Error() string {
+	return e.err.Error()
+}
+
+// Unwrap returns the underlying error
+func (e *retriedError) Unwrap() error {
+	return e.err
+}
+
+// IsRetried returns true if the source already retried the request that failed
+// with err so retrying the check again would only add to the load on the API
+func IsRetried(err error) bool {
+	var re *retriedError
+	return errors.As(err, &re)
+}
+
+// isRetryable returns true if a request that failed with err should be
+// retried
+func isRetryable(err error) bool {
+	var re *retryableError
+	if errors.As(err, &re) {
+		return true
+	}
+
+	var ne net.Error
+	return errors.As(err, &ne)
+}
+
+// retryAfter returns the delay requested by the Retry-After header of a
+// response
+func retryAfter(resp *http.Response) time.Duration {
+	header := resp.Header.Get("Retry-After")
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if t, err := http.ParseTime(header); err == nil {
+		return time.Until(t)
+	}
+
+	return 0
+}
+
+// limiter throttles requests using a token bucket and retries failed requests
+// with exponential backoff
+type limiter struct {
+	mu     sync.Mutex
+	config LimitConfig
+	tokens float64
+	last   time.Time
+}
+
+// newLimiter returns a new limiter
+func newLimiter(config LimitConfig) *limiter {
+	l := new(limiter)
+
+	if config.RateBurst < 1 {
+		config.RateBurst = 1
+	}
+	l.config = config
+	l.tokens = float64(config.RateBurst)
+	l.last = time.Now()
+
+	return l
+}
+
+// do runs a request honoring the rate limit. Requests failing with a
+// retryable error are retried until ctx is done.
+func (l *limiter) do(ctx context.Context, request func() error) error {
+	delay := time.Duration(l.config.RetryDelay) * time.Millisecond
+
+	for attempt := 0; ; attempt++ {
+		if err := l.wait(ctx); err != nil {
+			return err
+		}
+
+		err := request()
+		if err == nil || !isRetryable(err) {
+			return err
+		}
+		if attempt >= l.config.MaxRetries {
+			if attempt > 0 {
+				return &retriedError{err: err}
+			}
+			return err
+		}
+
+		// Honor the delay requested by the API
+		d := delay
+		var re *retryableError
+		if errors.As(err, &re) && re.retryAfter > d {
+			d = re.retryAfter
+		}
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+
+		delay *= 2
+		if delay > maxRetryDelay {
+			delay = maxRetryDelay
+		}
+	}
+}
+
+// wait blocks until the rate limit allows another request or ctx is done
+func (l *limiter) wait(ctx context.Context) error {
+	if l.config.RateLimit <= 0 {
+		return ctx.Err()
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.tokens += now.Sub(l.last).Seconds() * l.config.RateLimit
+	if max := float64(l.config.RateBurst); l.tokens > max {
+		l.tokens = max
+	}
+	l.last = now
+
+	if l.tokens < 1 {
+		// Wait for the next token while holding the lock so requests are
+		// served in order
+		d := time.Duration((1 - l.tokens) / l.config.RateLimit * float64(time.Second))
+		if err := sleep(ctx, d); err != nil {
+			return err
+		}
+		l.tokens = 1
+		l.last = time.Now()
+	}
+
+	l.tokens--
+
+	return nil
+}
+
+// sleep pauses for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,58 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestLimiterRetries(t *testing.T) {
+	l := newLimiter(LimitConfig{RateLimit: -1, MaxRetries: 2, RetryDelay: 1})
+
+	calls := 0
+	err := l.do(context.Background(), func() error {
+		calls++
+		return &retryableError{err: errors.New("Rate limited")}
+	})
+
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if !IsRetried(err) {
+		t.Errorf("IsRetried(%v) = false, want true", err)
+	}
+}
+
+func TestLimiterNoRetry(t *testing.T) {
+	l := newLimiter(LimitConfig{RateLimit: -1, MaxRetries: 2, RetryDelay: 1})
+
+	calls := 0
+	err := l.do(context.Background(), func() error {
+		calls++
+		return errors.New("Invalid request")
+	})
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if err == nil || IsRetried(err) {
+		t.Errorf("err = %v, want an error that wasn't retried", err)
+	}
+}
+
+func TestLimiterSuccess(t *testing.T) {
+	l := newLimiter(LimitConfig{RateLimit: -1, MaxRetries: 2, RetryDelay: 1})
+
+	calls := 0
+	err := l.do(context.Background(), func() error {
+		calls++
+		if calls == 1 {
+			return &retryableError{err: errors.New("Rate limited")}
+		}
+		return nil
+	})
+
+	if calls != 2 || err != nil {
+		t.Errorf("calls = %d, err = %v, want 2 calls without error", calls, err)
+	}
+}
@@ -1,7 +1,15 @@
 package source
 
 import (
-	gonc "github.com/billputer/go-namecheap"
+	"context"
+	"encoding/xml"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
 )
 
 // NameCheapConfig holds the configuration for the namecheap.com source
@@ -9,12 +17,113 @@ type NameCheapConfig struct {
 	APIUser  string
 	APIToken string
 	UserName string
+	ClientIP string // IP address sent with API requests (default: 127.0.0.1)
 	Enabled  bool
+	Sandbox  bool   // Use the sandbox environment
+	BaseURL  string // Overrides the API endpoint (i.e. for a local stand-in)
+	LimitConfig
+}
+
+const (
+	nameCheapAPIURL     = "https://api.namecheap.com/xml.response"
+	nameCheapSandboxURL = "https://api.sandbox.namecheap.com/xml.response"
+	nameCheapClientIP   = "127.0.0.1"
+)
+
+// Currency of all Namecheap prices
+const nameCheapCurrency = "USD"
+
+// Maximum number of domains per request
+const nameCheapBatchSize = 50
+
+// Failed price lookups are repeated after this delay
+const nameCheapPriceRetry = 5 * time.Minute
+
+// Namecheap allows 20 API requests per minute
+var nameCheapLimits = LimitConfig{
+	RateLimit:  0.33,
+	RateBurst:  1,
+	MaxRetries: 3,
+	RetryDelay: 3000,
 }
 
 // NameCheap handles namecheap.com API requests
 type NameCheap struct {
-	config *NameCheapConfig
+	config  *NameCheapConfig
+	limiter *limiter
+	client  *http.Client
+	baseURL string
+
+	mu     sync.Mutex
+	prices map[string]*nameCheapPriceLookup
+}
+
+// nameCheapPrice holds the one year prices of a TLD in micro-units
+type nameCheapPrice struct {
+	register int64
+	renew    int64
+}
+
+// nameCheapPriceLookup is the pricing lookup of a TLD shared by all checks.
+// done is closed once price and err are set.
+type nameCheapPriceLookup struct {
+	done    chan struct{}
+	price   nameCheapPrice
+	err     error
+	expires time.Time // Set for failed lookups that are repeated
+}
+
+type nameCheapError struct {
+	Number  int    `xml:"Number,attr"`
+	Message string `xml:",chardata"`
+}
+
+// Error numbers of the Namecheap API mapped to source errors
+var nameCheapErrors = map[int]error{
+	1010101: ErrAuth,           // APIUser is missing
+	1010102: ErrAuth,           // APIKey is missing
+	1011102: ErrAuth,           // API key is invalid or API access is disabled
+	1011150: ErrAuth,           // Client IP isn't whitelisted
+	1017150: ErrAuth,           // Client IP is disabled or locked
+	500000:  ErrRateLimited,    // Too many requests
+	2030280: ErrUnsupportedTLD, // TLD isn't supported by the API
+}
+
+type nameCheapCheckResponse struct {
+	Status  string           `xml:"Status,attr"`
+	Errors  []nameCheapError `xml:"Errors>Error"`
+	Results []struct {
+		Domain                   string  `xml:"Domain,attr"`
+		Available                bool    `xml:"Available,attr"`
+		ErrorNo                  int     `xml:"ErrorNo,attr"`
+		Description              string  `xml:"Description,attr"`
+		IsPremiumName            bool    `xml:"IsPremiumName,attr"`
+		PremiumRegistrationPrice float64 `xml:"PremiumRegistrationPrice,attr"`
+		PremiumRenewalPrice      float64 `xml:"PremiumRenewalPrice,attr"`
+	} `xml:"CommandResponse>DomainCheckResult"`
+}
+
+type nameCheapPricingResponse struct {
+	Status     string           `xml:"Status,attr"`
+	Errors     []nameCheapError `xml:"Errors>Error"`
+	Categories []struct {
+		Name     string `xml:"Name,attr"`
+		Products []struct {
+			Name   string `xml:"Name,attr"`
+			Prices []struct {
+				Duration     int     `xml:"Duration,attr"`
+				DurationType string  `xml:"DurationType,attr"`
+				Price        float64 `xml:"Price,attr"`
+				YourPrice    float64 `xml:"YourPrice,attr"`
+			} `xml:"Price"`
+		} `xml:"Product"`
+	} `xml:"CommandResponse>UserGetPricingResult>ProductType>ProductCategory"`
+}
+
+func init() {
+	Register(NameCheapSource, func() interface{} { return new(NameCheapConfig) }, func(config interface{}) (Source, error) {
+		return NewNameCheap(config.(*NameCheapConfig)), nil
+	})
 }
 
 // NewNameCheap returns a new NameCheap instance
@@ -22,19 +131,260 @@ func NewNameCheap(config *NameCheapConfig) Source {
 	nc := new(NameCheap)
 
 	nc.config = config
+	limits := config.LimitConfig.withDefaults(nameCheapLimits)
+	nc.limiter = newLimiter(limits)
+	nc.client = newHTTPClient(limits.Timeout)
+
+	nc.baseURL = nameCheapAPIURL
+	if config.Sandbox {
+		nc.baseURL = nameCheapSandboxURL
+	}
+	nc.baseURL = baseURL(config.BaseURL, nc.baseURL)
+	nc.prices = make(map[string]*nameCheapPriceLookup)
 
 	return nc
 }
 
+// Name returns the name of the source
+func (nc *NameCheap) Name() string {
+	return "namecheap"
+}
+
 // IsAvailable checks if a domain is available
-func (nc *NameCheap) IsAvailable(domain string) (bool, error) {
-	client := gonc.NewClient(nc.config.APIUser, nc.config.APIToken, nc.config.UserName)
+func (nc *NameCheap) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	results, err := nc.IsAvailableBatch(ctx, []string{domain})
+	if err != nil {
+		return Result{Domain: domain}, err
+	}
+	if len(results) == 0 {
+		return Result{Domain: domain}, errors.New("Empty API response")
+	}
+
+	return results[0].Result, results[0].Err
+}
+
+// BatchSize returns the maximum number of domains per request
+func (nc *NameCheap) BatchSize() int {
+	return nameCheapBatchSize
+}
+
+// IsAvailableBatch checks the availability of multiple domains with a single
+// request
+func (nc *NameCheap) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	var ncResponse nameCheapCheckResponse
+	err := nc.limiter.do(ctx, func() error {
+		err := nc.request(ctx, "namecheap.domains.check", url.Values{
+			"DomainList": {strings.Join(domains, ",")},
+		}, &ncResponse)
+		if err != nil {
+			return err
+		}
+
+		return nameCheapStatus(ncResponse.Status, ncResponse.Errors, "Couldn't check domains")
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, 0, len(ncResponse.Results))
+	for _, r := range ncResponse.Results {
+		result := Result{
+			Domain:     r.Domain,
+			Definitive: true,
+		}
+		if r.ErrorNo != 0 {
+			err := nameCheapError{Number: r.ErrorNo, Message: r.Description}.err()
+			results = append(results, BatchResult{Result: result, Err: err})
+			continue
+		}
+
+		switch {
+		case r.Available && r.IsPremiumName:
+			result.Status = StatusPremium
+			result.Price = micros(r.PremiumRegistrationPrice)
+			result.RenewalPrice = micros(r.PremiumRenewalPrice)
+			result.Currency = nameCheapCurrency
+			result.Period = 1
+		case r.Available:
+			result.Status = StatusAvailable
+
+			// Prices are optional, failed lookups are ignored
+			if price, err := nc.price(ctx, tld(r.Domain)); err == nil {
+				result.Price = price.register
+				result.RenewalPrice = price.renew
+				result.Currency = nameCheapCurrency
+				result.Period = 1
+			}
+		default:
+			result.Status = StatusTaken
+		}
+
+		results = append(results, BatchResult{Result: result})
+	}
+
+	return results, nil
+}
+
+// price returns the standard prices of a TLD. Prices are fetched once per TLD,
+// failed lookups are repeated after nameCheapPriceRetry unless the error is
+// permanent.
+func (nc *NameCheap) price(ctx context.Context, tld string) (nameCheapPrice, error) {
+	nc.mu.Lock()
+	lookup, ok := nc.prices[tld]
+	if ok && !lookup.expires.IsZero() && time.Now().After(lookup.expires) {
+		ok = false
+	}
+	if !ok {
+		lookup = &nameCheapPriceLookup{done: make(chan struct{})}
+		nc.prices[tld] = lookup
+	}
+	nc.mu.Unlock()
+
+	if !ok {
+		lookup.price, lookup.err = nc.fetchPrice(ctx, tld)
+
+		nc.mu.Lock()
+		switch {
+		case lookup.err != nil && ctx.Err() != nil:
+			// Lookups cut short by a cancelled search are repeated
+			delete(nc.prices, tld)
+		case lookup.err != nil && !IsPermanent(lookup.err):
+			lookup.expires = time.Now().Add(nameCheapPriceRetry)
+		}
+		nc.mu.Unlock()
+		close(lookup.done)
+	}
+
+	select {
+	case <-lookup.done:
+		return lookup.price, lookup.err
+	case <-ctx.Done():
+		return nameCheapPrice{}, ctx.Err()
+	}
+}
+
+// fetchPrice fetches the standard prices of a TLD
+func (nc *NameCheap) fetchPrice(ctx context.Context, tld string) (nameCheapPrice, error) {
+	var price nameCheapPrice
+
+	var ncResponse nameCheapPricingResponse
+	err := nc.limiter.do(ctx, func() error {
+		err := nc.request(ctx, "namecheap.users.getPricing", url.Values{
+			"ProductType": {"DOMAIN"},
+			"ProductName": {tld},
+		}, &ncResponse)
+		if err != nil {
+			return err
+		}
+
+		return nameCheapStatus(ncResponse.Status, ncResponse.Errors, "Couldn't fetch prices")
+	})
+	if err != nil {
+		return price, err
+	}
+
+	for _, category := range ncResponse.Categories {
+		for _, product := range category.Products {
+			if !strings.EqualFold(product.Name, tld) {
+				continue
+			}
+
+			for _, p := range product.Prices {
+				if p.Duration != 1 || !strings.EqualFold(p.DurationType, "YEAR") {
+					continue
+				}
+
+				amount := p.YourPrice
+				if amount == 0 {
+					amount = p.Price
+				}
+
+				switch strings.ToLower(category.Name) {
+				case "register":
+					price.register = int64(amount * 1000000)
+				case "renew":
+					price.renew = int64(amount * 1000000)
+				}
+			}
+		}
+	}
+
+	return price, nil
+}
+
+// request performs an API request and decodes the XML response into out
+func (nc *NameCheap) request(ctx context.Context, command string, params url.Values, out interface{}) error {
+	clientIP := nc.config.ClientIP
+	if clientIP == "" {
+		clientIP = nameCheapClientIP
+	}
+
+	params.Set("ApiUser", nc.config.APIUser)
+	params.Set("ApiKey", nc.config.APIToken)
+	params.Set("UserName", nc.config.UserName)
+	params.Set("ClientIp", clientIP)
+	params.Set("Command", command)
 
-	result, err := client.DomainsCheck(domain)
+	req, err := http.NewRequest("GET", nc.baseURL+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := send(ctx, nc.client, req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return withRetryAfter(statusError(resp.StatusCode, resp.Status), resp)
+	}
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't read API response"}}
+	}
+
+	if err := xml.Unmarshal(body, out); err != nil {
+		return errors.New("Couldn't parse API response")
+	}
+
+	return nil
+}
+
+// nameCheapStatus returns the error of a failed API command or nil if the
+// command succeeded
+func nameCheapStatus(status string, errs []nameCheapError, message string) error {
+	if status == "OK" {
+		return nil
+	}
+	if len(errs) > 0 {
+		return errs[0].err()
+	}
+
+	return errors.New(message)
+}
+
+// err converts an API error to a source error
+func (e nameCheapError) err() error {
+	message := strings.TrimSpace(e.Message)
+
+	kind, ok := nameCheapErrors[e.Number]
+	switch {
+	case !ok:
+		return errors.New(message)
+	case kind == ErrRateLimited:
+		return &retryableError{err: &Error{Kind: kind, Message: message}}
+	}
+
+	return &Error{Kind: kind, Message: message}
+}
 
-	if err == nil {
-		return result[0].Available, err
+// tld returns the TLD of a domain
+func tld(domain string) string {
+	if i := strings.Index(domain, "."); i != -1 {
+		return strings.ToLower(domain[i+1:])
 	}
 
-	return false, err
+	return ""
 }
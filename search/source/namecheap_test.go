@@ -0,0 +1,112 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+)
+
+func TestNameCheapIsAvailableBatch(t *testing.T) {
+	var pricingRequests int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("ApiUser") != "user" || query.Get("ApiKey") != "token" {
+			fmt.Fprint(w, `<ApiResponse Status="ERROR"><Errors><Error Number="1011102">API Key is invalid</Error></Errors></ApiResponse>`)
+			return
+		}
+
+		switch query.Get("Command") {
+		case "namecheap.domains.check":
+			fmt.Fprint(w, `<ApiResponse Status="OK"><CommandResponse>`)
+			for _, domain := range strings.Split(query.Get("DomainList"), ",") {
+				switch domain {
+				case "free.com", "other.com", "free.io", "free.net":
+					fmt.Fprintf(w, `<DomainCheckResult Domain="%s" Available="true"/>`, domain)
+				case "premium.com":
+					fmt.Fprintf(w, `<DomainCheckResult Domain="%s" Available="true" IsPremiumName="true" PremiumRegistrationPrice="2500" PremiumRenewalPrice="25"/>`, domain)
+				default:
+					fmt.Fprintf(w, `<DomainCheckResult Domain="%s" Available="false"/>`, domain)
+				}
+			}
+			fmt.Fprint(w, `</CommandResponse></ApiResponse>`)
+		case "namecheap.users.getPricing":
+			atomic.AddInt32(&pricingRequests, 1)
+			switch query.Get("ProductName") {
+			case "io":
+				fmt.Fprint(w, `<ApiResponse Status="ERROR"><Errors><Error Number="2030280">TLD is not supported</Error></Errors></ApiResponse>`)
+				return
+			case "net":
+				fmt.Fprint(w, `<ApiResponse Status="ERROR"><Errors><Error Number="2011170">Pricing unavailable</Error></Errors></ApiResponse>`)
+				return
+			}
+			fmt.Fprint(w, `<ApiResponse Status="OK"><CommandResponse><UserGetPricingResult><ProductType Name="domains">
+<ProductCategory Name="register"><Product Name="com"><Price Duration="1" DurationType="YEAR" Price="10.98" YourPrice="9.58"/></Product></ProductCategory>
+<ProductCategory Name="renew"><Product Name="com"><Price Duration="1" DurationType="YEAR" Price="14.98" YourPrice="0"/></Product></ProductCategory>
+</ProductType></UserGetPricingResult></CommandResponse></ApiResponse>`)
+		}
+	}))
+	defer server.Close()
+
+	newNameCheap := func(token string) BatchSource {
+		return NewNameCheap(&NameCheapConfig{
+			APIUser:     "user",
+			APIToken:    token,
+			BaseURL:     server.URL,
+			LimitConfig: LimitConfig{RateLimit: -1, MaxRetries: -1},
+		}).(BatchSource)
+	}
+
+	nc := newNameCheap("token")
+	results, err := nc.IsAvailableBatch(context.Background(), []string{"free.com", "other.com", "premium.com", "taken.com", "free.io", "free.net"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		status  Status
+		price   int64
+		renewal int64
+	}{
+		{StatusAvailable, 9580000, 14980000},
+		{StatusAvailable, 9580000, 14980000},
+		{StatusPremium, 2500000000, 25000000},
+		{StatusTaken, 0, 0},
+		{StatusAvailable, 0, 0},
+		{StatusAvailable, 0, 0},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("IsAvailableBatch() returned %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Err != nil || r.Status != w.status || r.Price != w.price || r.RenewalPrice != w.renewal {
+			t.Errorf("%s = %s %d/%d (error %v), want %s %d/%d", r.Domain, r.Status, r.Price, r.RenewalPrice, r.Err, w.status, w.price, w.renewal)
+		}
+	}
+
+	// Prices are fetched once per TLD, failed lookups aren't repeated right away
+	if _, err := nc.IsAvailableBatch(context.Background(), []string{"free.com", "free.io", "free.net"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := atomic.LoadInt32(&pricingRequests); n != 3 {
+		t.Errorf("Made %d pricing requests, want 3", n)
+	}
+
+	// Permanent errors are kept, other failed lookups expire
+	prices := nc.(*NameCheap).prices
+	if !prices["io"].expires.IsZero() {
+		t.Errorf("Lookup failed with a permanent error expires at %s", prices["io"].expires)
+	}
+	if prices["net"].expires.IsZero() {
+		t.Error("Lookup failed with a temporary error doesn't expire")
+	}
+
+	if _, err := newNameCheap("invalid").IsAvailableBatch(context.Background(), []string{"free.com"}); !errors.Is(err, ErrAuth) {
+		t.Errorf("IsAvailableBatch() error = %v, want %v", err, ErrAuth)
+	}
+}
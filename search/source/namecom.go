@@ -0,0 +1,151 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+)
+
+const nameComAPIURL = "https://api.name.com"
+
+// Maximum number of domains per availability request
+const nameComBatchSize = 50
+
+// NameComConfig holds the configuration for the name.com source
+type NameComConfig struct {
+	UserName string
+	Token    string
+	BaseURL  string
+	Enabled  bool
+	LimitConfig
+}
+
+// Name.com allows 20 requests per second
+var nameComLimits = LimitConfig{
+	RateLimit:  20,
+	RateBurst:  20,
+	MaxRetries: 3,
+	RetryDelay: 1000,
+}
+
+type nameComResponse struct {
+	Message string
+	Results []struct {
+		DomainName    string
+		Purchasable   bool
+		Premium       bool
+		PurchasePrice float64
+		RenewalPrice  float64
+	}
+}
+
+// NameCom handles name.com API requests
+type NameCom struct {
+	config  *NameComConfig
+	limiter *limiter
+	client  *http.Client
+	baseURL string
+}
+
+func init() {
+	Register(NameComSource, func() interface{} { return new(NameComConfig) }, func(config interface{}) (Source, error) {
+		return NewNameCom(config.(*NameComConfig)), nil
+	})
+}
+
+// NewNameCom returns a new NameCom instance
+func NewNameCom(config *NameComConfig) Source {
+	nc := new(NameCom)
+
+	nc.config = config
+	limits := config.LimitConfig.withDefaults(nameComLimits)
+	nc.limiter = newLimiter(limits)
+	nc.client = newHTTPClient(limits.Timeout)
+	nc.baseURL = baseURL(config.BaseURL, nameComAPIURL)
+
+	return nc
+}
+
+// Name returns the name of the source
+func (nc *NameCom) Name() string {
+	return "namecom"
+}
+
+// IsAvailable checks if a domain is available
+func (nc *NameCom) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	results, err := nc.IsAvailableBatch(ctx, []string{domain})
+	if err != nil {
+		return Result{Domain: domain}, err
+	}
+	if len(results) == 0 {
+		return Result{Domain: domain}, errors.New("Empty API response")
+	}
+
+	return results[0].Result, results[0].Err
+}
+
+// BatchSize returns the maximum number of domains per request
+func (nc *NameCom) BatchSize() int {
+	return nameComBatchSize
+}
+
+// IsAvailableBatch checks the availability of multiple domains with a single
+// request
+func (nc *NameCom) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	body, err := json.Marshal(map[string][]string{"domainNames": domains})
+	if err != nil {
+		return nil, err
+	}
+
+	var ncResponse nameComResponse
+	err = nc.limiter.do(ctx, func() error {
+		req, err := http.NewRequest("POST", nc.baseURL+"/v4/domains:checkAvailability", bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Content-Type", "application/json")
+		req.SetBasicAuth(nc.config.UserName, nc.config.Token)
+
+		status, err := doJSON(ctx, nc.client, req, &ncResponse)
+		if err != nil {
+			return err
+		}
+		if status != http.StatusOK {
+			return statusError(status, ncResponse.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]BatchResult, 0, len(ncResponse.Results))
+	for _, r := range ncResponse.Results {
+		result := Result{
+			Domain:     r.DomainName,
+			Definitive: true,
+		}
+
+		switch {
+		case !r.Purchasable:
+			result.Status = StatusTaken
+		case r.Premium:
+			result.Status = StatusPremium
+		default:
+			result.Status = StatusAvailable
+		}
+
+		if r.Purchasable {
+			result.Price = micros(r.PurchasePrice)
+			result.RenewalPrice = micros(r.RenewalPrice)
+			result.Currency = "USD"
+			result.Period = 1
+		}
+
+		results = append(results, BatchResult{Result: result})
+	}
+
+	return results, nil
+}
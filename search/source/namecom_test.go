@@ -0,0 +1,86 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestNameComIsAvailableBatch(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v4/domains:checkAvailability" {
+			http.NotFound(w, r)
+			return
+		}
+		if user, token, ok := r.BasicAuth(); !ok || user != "user" || token != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			w.Write([]byte("Unauthorized"))
+			return
+		}
+
+		var request struct{ DomainNames []string }
+		json.NewDecoder(r.Body).Decode(&request)
+
+		type result struct {
+			DomainName    string  `json:"domainName"`
+			Purchasable   bool    `json:"purchasable"`
+			Premium       bool    `json:"premium"`
+			PurchasePrice float64 `json:"purchasePrice"`
+			RenewalPrice  float64 `json:"renewalPrice"`
+		}
+		var results []result
+		for _, domain := range request.DomainNames {
+			switch domain {
+			case "free.com":
+				results = append(results, result{DomainName: domain, Purchasable: true, PurchasePrice: 8.99, RenewalPrice: 12.99})
+			case "premium.com":
+				results = append(results, result{DomainName: domain, Purchasable: true, Premium: true, PurchasePrice: 3000})
+			default:
+				results = append(results, result{DomainName: domain})
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"results": results})
+	}))
+	defer server.Close()
+
+	newNameCom := func(token string) BatchSource {
+		return NewNameCom(&NameComConfig{
+			UserName:    "user",
+			Token:       token,
+			BaseURL:     server.URL,
+			LimitConfig: LimitConfig{RateLimit: -1, MaxRetries: -1},
+		}).(BatchSource)
+	}
+
+	results, err := newNameCom("token").IsAvailableBatch(context.Background(), []string{"free.com", "premium.com", "taken.com"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		status  Status
+		price   int64
+		renewal int64
+	}{
+		{StatusAvailable, 8990000, 12990000},
+		{StatusPremium, 3000000000, 0},
+		{StatusTaken, 0, 0},
+	}
+	if len(results) != len(want) {
+		t.Fatalf("IsAvailableBatch() returned %d results, want %d", len(results), len(want))
+	}
+	for i, w := range want {
+		r := results[i]
+		if r.Err != nil || r.Status != w.status || r.Price != w.price || r.RenewalPrice != w.renewal {
+			t.Errorf("%s = %s %d/%d (error %v), want %s %d/%d", r.Domain, r.Status, r.Price, r.RenewalPrice, r.Err, w.status, w.price, w.renewal)
+		}
+	}
+
+	// Failed requests without a JSON body are reported by their status
+	if _, err := newNameCom("invalid").IsAvailableBatch(context.Background(), []string{"free.com"}); !errors.Is(err, ErrAuth) {
+		t.Errorf("IsAvailableBatch() error = %v, want %v", err, ErrAuth)
+	}
+}
@@ -0,0 +1,151 @@
+package source
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+)
+
+const porkbunAPIURL = "https://api.porkbun.com/api/json/v3"
+
+// PorkbunConfig holds the configuration for the porkbun.com source
+type PorkbunConfig struct {
+	APIKey       string
+	SecretAPIKey string
+	BaseURL      string
+	Enabled      bool
+	LimitConfig
+}
+
+// Porkbun allows one availability check per 10 seconds
+var porkbunLimits = LimitConfig{
+	RateLimit:  0.1,
+	RateBurst:  1,
+	MaxRetries: 3,
+	RetryDelay: 10000,
+}
+
+type porkbunResponse struct {
+	Status   string
+	Message  string
+	Response struct {
+		Avail        string
+		Price        string
+		RegularPrice string
+		Premium      string
+		Additional   struct {
+			Renewal struct {
+				Price string
+			}
+		}
+	}
+}
+
+// Porkbun handles porkbun.com API requests
+type Porkbun struct {
+	config  *PorkbunConfig
+	limiter *limiter
+	client  *http.Client
+	baseURL string
+}
+
+func init() {
+	Register(PorkbunSource, func() interface{} { return new(PorkbunConfig) }, func(config interface{}) (Source, error) {
+		return NewPorkbun(config.(*PorkbunConfig)), nil
+	})
+}
+
+// NewPorkbun returns a new Porkbun instance
+func NewPorkbun(config *PorkbunConfig) Source {
+	pb := new(Porkbun)
+
+	pb.config = config
+	limits := config.LimitConfig.withDefaults(porkbunLimits)
+	pb.limiter = newLimiter(limits)
+	pb.client = newHTTPClient(limits.Timeout)
+	pb.baseURL = baseURL(config.BaseURL, porkbunAPIURL)
+
+	return pb
+}
+
+// Name returns the name of the source
+func (pb *Porkbun) Name() string {
+	return "porkbun"
+}
+
+// IsAvailable checks if a domain is available
+func (pb *Porkbun) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result := Result{Domain: domain}
+
+	body, err := json.Marshal(map[string]string{
+		"apikey":       pb.config.APIKey,
+		"secretapikey": pb.config.SecretAPIKey,
+	})
+	if err != nil {
+		return result, err
+	}
+
+	var pbResponse porkbunResponse
+	err = pb.limiter.do(ctx, func() error {
+		req, err := http.NewRequest("POST", pb.baseURL+"/domain/checkDomain/"+domain, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Add("Content-Type", "application/json")
+
+		status, err := doJSON(ctx, pb.client, req, &pbResponse)
+		if err != nil {
+			return err
+		}
+		if pbResponse.Status != "SUCCESS" {
+			return porkbunError(status, pbResponse.Message)
+		}
+		return nil
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Definitive = true
+	switch {
+	case pbResponse.Response.Avail != "yes":
+		result.Status = StatusTaken
+		return result, nil
+	case pbResponse.Response.Premium == "yes":
+		result.Status = StatusPremium
+	default:
+		result.Status = StatusAvailable
+	}
+
+	result.Price = parseMicros(pbResponse.Response.Price)
+	result.RenewalPrice = parseMicros(pbResponse.Response.Additional.Renewal.Price)
+	result.Currency = "USD"
+	result.Period = 1
+
+	return result, nil
+}
+
+// porkbunError maps a failed request to a source error. The API only returns
+// a message so the error kind is derived from it.
+func porkbunError(status int, message string) error {
+	lower := strings.ToLower(message)
+	switch {
+	case strings.Contains(lower, "api key"):
+		return &Error{Kind: ErrAuth, Message: message}
+	case strings.Contains(lower, "limit"):
+		return &retryableError{err: &Error{Kind: ErrRateLimited, Message: message}}
+	case strings.Contains(lower, "not supported"):
+		return &Error{Kind: ErrUnsupportedTLD, Message: message}
+	case strings.Contains(lower, "invalid domain"):
+		return &Error{Kind: ErrInvalidDomain, Message: message}
+	case status >= 400:
+		return statusError(status, message)
+	case message != "":
+		return errors.New(message)
+	}
+
+	return errors.New("Couldn't check domain")
+}
@@ -0,0 +1,63 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPorkbunIsAvailable(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var keys map[string]string
+		if err := json.NewDecoder(r.Body).Decode(&keys); err != nil || keys["apikey"] != "key" || keys["secretapikey"] != "secret" {
+			w.Write([]byte(`{"status": "ERROR", "message": "Invalid API key"}`))
+			return
+		}
+
+		switch strings.TrimPrefix(r.URL.Path, "/domain/checkDomain/") {
+		case "free.com":
+			w.Write([]byte(`{"status": "SUCCESS", "response": {"avail": "yes", "price": "9.73", "premium": "no", "additional": {"renewal": {"price": "10.37"}}}}`))
+		case "premium.com":
+			w.Write([]byte(`{"status": "SUCCESS", "response": {"avail": "yes", "price": "2500.00", "premium": "yes"}}`))
+		case "taken.com":
+			w.Write([]byte(`{"status": "SUCCESS", "response": {"avail": "no"}}`))
+		default:
+			w.Write([]byte(`{"status": "ERROR", "message": "Invalid domain"}`))
+		}
+	}))
+	defer server.Close()
+
+	pb := NewPorkbun(&PorkbunConfig{
+		APIKey:       "key",
+		SecretAPIKey: "secret",
+		BaseURL:      server.URL,
+		LimitConfig:  LimitConfig{RateLimit: -1, MaxRetries: -1},
+	})
+
+	tests := []struct {
+		domain  string
+		status  Status
+		price   int64
+		renewal int64
+		wantErr bool
+	}{
+		{"free.com", StatusAvailable, 9730000, 10370000, false},
+		{"premium.com", StatusPremium, 2500000000, 0, false},
+		{"taken.com", StatusTaken, 0, 0, false},
+		{"invalid", StatusUnknown, 0, 0, true},
+	}
+
+	for _, tt := range tests {
+		result, err := pb.IsAvailable(context.Background(), tt.domain)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IsAvailable(%s) error = %v, want error %t", tt.domain, err, tt.wantErr)
+			continue
+		}
+		if result.Status != tt.status || result.Price != tt.price || result.RenewalPrice != tt.renewal {
+			t.Errorf("IsAvailable(%s) = %s %d/%d, want %s %d/%d", tt.domain, result.Status, result.Price, result.RenewalPrice, tt.status, tt.price, tt.renewal)
+		}
+	}
+}
@@ -0,0 +1,240 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strings"
+	"sync"
+)
+
+const rdapBootstrapURL = "https://data.iana.org/rdap/dns.json"
+
+// RDAPConfig holds the configuration for the RDAP source
+type RDAPConfig struct {
+	Enabled       bool
+	BootstrapFile string // Local copy of the IANA bootstrap file
+	BootstrapURL  string
+	LimitConfig
+}
+
+var rdapLimits = LimitConfig{
+	RateLimit:  10,
+	RateBurst:  10,
+	MaxRetries: 3,
+	RetryDelay: 1000,
+}
+
+// rdapBootstrap is the IANA RDAP bootstrap file for DNS (RFC 7484)
+type rdapBootstrap struct {
+	Services [][][]string
+}
+
+// RDAP handles checking availability of domain names via registry RDAP
+// servers
+type RDAP struct {
+	config  *RDAPConfig
+	limiter *limiter
+	client  *http.Client
+
+	mu      sync.Mutex
+	servers map[string]string
+}
+
+func init() {
+	Register(RDAPSource, func() interface{} { return new(RDAPConfig) }, func(config interface{}) (Source, error) {
+		return NewRDAP(config.(*RDAPConfig)), nil
+	})
+}
+
+// NewRDAP returns a new RDAP instance
+func NewRDAP(config *RDAPConfig) Source {
+	r := new(RDAP)
+
+	r.config = config
+	limits := config.LimitConfig.withDefaults(rdapLimits)
+	r.limiter = newLimiter(limits)
+	r.client = newHTTPClient(limits.Timeout)
+
+	return r
+}
+
+// Name returns the name of the source
+func (r *RDAP) Name() string {
+	return "rdap"
+}
+
+// IsAvailable checks if a domain is available
+func (r *RDAP) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result := Result{Domain: domain}
+
+	if err := r.bootstrap(ctx); err != nil {
+		return result, err
+	}
+
+	server, ok := r.server(domain)
+	if !ok {
+		return result, &Error{Kind: ErrUnsupportedTLD, Message: "No RDAP server for " + domain}
+	}
+
+	var status int
+	err := r.limiter.do(ctx, func() error {
+		var err error
+		status, err = r.request(ctx, server+"domain/"+domain)
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Definitive = true
+	switch status {
+	case http.StatusOK:
+		result.Status = StatusTaken
+	case http.StatusNotFound:
+		result.Status = StatusAvailable
+	default:
+		return result, fmt.Errorf("Unexpected RDAP response: %d", status)
+	}
+
+	return result, nil
+}
+
+// request performs a domain lookup and returns the HTTP status code
+func (r *RDAP) request(ctx context.Context, url string) (int, error) {
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Add("Accept", "application/rdap+json")
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		return 0, &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't connect to RDAP server"}}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500 {
+		return 0, withRetryAfter(statusError(resp.StatusCode, resp.Status), resp)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// server returns the RDAP base URL for a domain
+func (r *RDAP) server(domain string) (string, bool) {
+	labels := strings.Split(strings.ToLower(domain), ".")
+
+	// Use the longest matching entry
+	for i := 1; i < len(labels); i++ {
+		if server, ok := r.servers[strings.Join(labels[i:], ".")]; ok {
+			return server, true
+		}
+	}
+
+	return "", false
+}
+
+// bootstrap loads the bootstrap file unless it has been loaded already. Failed
+// loads are retried on the next call.
+func (r *RDAP) bootstrap(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.servers != nil {
+		return nil
+	}
+
+	servers, err := r.loadBootstrap(ctx)
+	if err != nil {
+		return err
+	}
+	r.servers = servers
+
+	return nil
+}
+
+// loadBootstrap loads the bootstrap file mapping TLDs to RDAP servers
+func (r *RDAP) loadBootstrap(ctx context.Context) (map[string]string, error) {
+	data, err := r.readBootstrap(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var bootstrap rdapBootstrap
+	if err := json.Unmarshal(data, &bootstrap); err != nil {
+		return nil, errors.New("Couldn't parse RDAP bootstrap file")
+	}
+
+	servers := make(map[string]string)
+	for _, service := range bootstrap.Services {
+		if len(service) < 2 || len(service[1]) == 0 {
+			continue
+		}
+
+		// Prefer HTTPS servers
+		server := service[1][0]
+		for _, s := range service[1] {
+			if strings.HasPrefix(s, "https://") {
+				server = s
+				break
+			}
+		}
+		if !strings.HasSuffix(server, "/") {
+			server += "/"
+		}
+
+		for _, tld := range service[0] {
+			servers[strings.ToLower(tld)] = server
+		}
+	}
+
+	return servers, nil
+}
+
+// readBootstrap reads the bootstrap file from disk or downloads it
+func (r *RDAP) readBootstrap(ctx context.Context) ([]byte, error) {
+	if r.config.BootstrapFile != "" {
+		data, err := ioutil.ReadFile(r.config.BootstrapFile)
+		if err != nil {
+			return nil, fmt.Errorf("Couldn't read RDAP bootstrap file: %s", r.config.BootstrapFile)
+		}
+		return data, nil
+	}
+
+	url := r.config.BootstrapURL
+	if url == "" {
+		url = rdapBootstrapURL
+	}
+
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := r.client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, &Error{Kind: ErrTransient, Message: "Couldn't download RDAP bootstrap file"}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("Couldn't download RDAP bootstrap file: %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return nil, &Error{Kind: ErrTransient, Message: "Couldn't download RDAP bootstrap file"}
+	}
+
+	return data, nil
+}
@@ -0,0 +1,62 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRDAPIsAvailable(t *testing.T) {
+	var server *httptest.Server
+	failBootstrap := true
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/dns.json":
+			if failBootstrap {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			fmt.Fprintf(w, `{"services": [[["test", "co.test"], ["%s/rdap"]]]}`, server.URL)
+		case "/rdap/domain/taken.test":
+			w.Write([]byte("{}"))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	r := NewRDAP(&RDAPConfig{
+		BootstrapURL: server.URL + "/dns.json",
+		LimitConfig:  LimitConfig{RateLimit: -1, MaxRetries: -1},
+	})
+
+	// Failed bootstrap downloads are repeated by the next check
+	if _, err := r.IsAvailable(context.Background(), "taken.test"); err == nil {
+		t.Fatal("IsAvailable() error = nil, want bootstrap error")
+	}
+	failBootstrap = false
+
+	tests := []struct {
+		domain  string
+		status  Status
+		wantErr bool
+	}{
+		{"taken.test", StatusTaken, false},
+		{"free.test", StatusAvailable, false},
+		{"free.co.test", StatusAvailable, false},
+		{"foo.unsupported", StatusUnknown, true},
+	}
+
+	for _, tt := range tests {
+		result, err := r.IsAvailable(context.Background(), tt.domain)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IsAvailable(%s) error = %v, want error %t", tt.domain, err, tt.wantErr)
+			continue
+		}
+		if result.Status != tt.status {
+			t.Errorf("IsAvailable(%s) = %s, want %s", tt.domain, result.Status, tt.status)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package source
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+)
+
+// ConfigFunc returns a pointer to a new config struct of a source type. The
+// source's config section is decoded into it.
+type ConfigFunc func() interface{}
+
+// NewFunc creates a source from a config returned by the source type's
+// ConfigFunc. It returns an error if the config is invalid.
+type NewFunc func(config interface{}) (Source, error)
+
+type registration struct {
+	config     ConfigFunc
+	newSource  NewFunc
+	configType reflect.Type
+}
+
+var (
+	registryMu sync.RWMutex
+	registry   = make(map[string]registration)
+)
+
+// Register makes a source type available by name. It panics if the name is
+// registered twice.
+func Register(sourceType string, config ConfigFunc, newSource NewFunc) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+
+	if _, ok := registry[sourceType]; ok {
+		panic("Source registered twice: " + sourceType)
+	}
+
+	registry[sourceType] = registration{
+		config:     config,
+		newSource:  newSource,
+		configType: reflect.TypeOf(config()),
+	}
+}
+
+// Types returns the names of all registered source types in alphabetical
+// order
+func Types() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	types := make([]string, 0, len(registry))
+	for t := range registry {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+
+	return types
+}
+
+// IsRegistered returns true if a source type is registered
+func IsRegistered(sourceType string) bool {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	_, ok := registry[sourceType]
+	return ok
+}
+
+// NewConfig returns a new config for a source type to decode the source's
+// config section into
+func NewConfig(sourceType string) (interface{}, error) {
+	r, err := lookup(sourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.config(), nil
+}
+
+// Get returns a search source of the given type
+func Get(config interface{}, sourceType string) (Source, error) {
+	r, err := lookup(sourceType)
+	if err != nil {
+		return nil, err
+	}
+
+	if reflect.TypeOf(config) != r.configType {
+		return nil, fmt.Errorf("Invalid config for source %s: %T", sourceType, config)
+	}
+
+	return r.newSource(config)
+}
+
+// lookup returns the registration of a source type
+func lookup(sourceType string) (registration, error) {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+
+	r, ok := registry[sourceType]
+	if !ok {
+		return r, fmt.Errorf("Unknown source type: %s", sourceType)
+	}
+
+	return r, nil
+}
+
+// WithName returns a source that reports itself under a different name, i.e.
+// to tell multiple accounts of the same registrar apart
+func WithName(s Source, name string) Source {
+	if bs, ok := s.(BatchSource); ok {
+		return &namedBatchSource{namedSource{s, name}, bs}
+	}
+
+	return &namedSource{s, name}
+}
+
+type namedSource struct {
+	source Source
+	name   string
+}
+
+// Name returns the name of the source
+func (n *namedSource) Name() string {
+	return n.name
+}
+
+// IsAvailable checks if a domain is available
+func (n *namedSource) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result, err := n.source.IsAvailable(ctx, domain)
+	if result.Source == "" {
+		result.Source = n.name
+	}
+
+	return result, err
+}
+
+type namedBatchSource struct {
+	namedSource
+	batch BatchSource
+}
+
+// BatchSize returns the maximum number of domains per request
+func (n *namedBatchSource) BatchSize() int {
+	return n.batch.BatchSize()
+}
+
+// IsAvailableBatch checks the availability of multiple domains
+func (n *namedBatchSource) IsAvailableBatch(ctx context.Context, domains []string) ([]BatchResult, error) {
+	results, err := n.batch.IsAvailableBatch(ctx, domains)
+
+	return withSource(n, results), err
+}
@@ -0,0 +1,50 @@
+package source
+
+import (
+	"context"
+	"testing"
+)
+
+func TestGet(t *testing.T) {
+	tests := []struct {
+		name       string
+		sourceType string
+		config     interface{}
+		wantErr    bool
+	}{
+		{"valid", DNSSource, &DNSConfig{RecordTypes: []string{"ns", "SOA"}}, false},
+		{"unknown type", "unknown", &DNSConfig{}, true},
+		{"config of another type", DNSSource, &RDAPConfig{}, true},
+		{"invalid record type", DNSSource, &DNSConfig{RecordTypes: []string{"FOO"}}, true},
+		{"exec without command", ExecSource, &ExecConfig{}, true},
+		{"zone file without files", ZoneFileSource, &ZoneFileConfig{}, true},
+	}
+
+	for _, tt := range tests {
+		s, err := Get(tt.config, tt.sourceType)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("%s: Get() error = %v, want error %t", tt.name, err, tt.wantErr)
+			continue
+		}
+		if err == nil && s.Name() != tt.sourceType {
+			t.Errorf("%s: Get() = %s, want %s", tt.name, s.Name(), tt.sourceType)
+		}
+	}
+}
+
+func TestWithName(t *testing.T) {
+	s := WithName(&fakeBatchSource{fakeSource{name: "registrar", statuses: map[string]Status{"free.com": StatusAvailable}}}, "work")
+
+	bs, ok := s.(BatchSource)
+	if !ok {
+		t.Fatal("WithName() of a batch source isn't a batch source")
+	}
+	if s.Name() != "work" {
+		t.Errorf("Name() = %s, want work", s.Name())
+	}
+
+	results, err := bs.IsAvailableBatch(context.Background(), []string{"free.com"})
+	if err != nil || len(results) != 1 || results[0].Source != "work" {
+		t.Errorf("IsAvailableBatch() = %+v, %v, want result from work", results, err)
+	}
+}
@@ -1,26 +1,121 @@
 package source
 
+import "context"
+
+// Source types
+const (
+	CloudflareSource = "cloudflare"
+	DNSSource        = "dns"
+	ExecSource       = "exec"
+	GandiSource      = "gandi"
+	GoDaddySource    = "godaddy"
+	NameCheapSource  = "namecheap"
+	NameComSource    = "namecom"
+	PorkbunSource    = "porkbun"
+	RDAPSource       = "rdap"
+	WHOISSource      = "whois"
+	ZoneFileSource   = "zonefile"
+)
+
+// Status describes the availability state of a domain
+type Status int
+
 const (
-	DNSSource       = "dns"
-	GoDaddySource   = "gds"
-	NameCheapSource = "ncs"
+	StatusUnknown Status = iota
+	StatusAvailable
+	StatusTaken
+	StatusPremium
+	StatusReserved
 )
 
+var statusNames = map[Status]string{
+	StatusUnknown:   "unknown",
+	StatusAvailable: "available",
+	StatusTaken:     "taken",
+	StatusPremium:   "premium",
+	StatusReserved:  "reserved",
+}
+
+// String returns the name of a status
+func (s Status) String() string {
+	if name, ok := statusNames[s]; ok {
+		return name
+	}
+	return statusNames[StatusUnknown]
+}
+
+// ParseStatus returns the status for a status name
+func ParseStatus(name string) Status {
+	for s, n := range statusNames {
+		if n == name {
+			return s
+		}
+	}
+	return StatusUnknown
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (s Status) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (s *Status) UnmarshalText(text []byte) error {
+	*s = ParseStatus(string(text))
+	return nil
+}
+
+// Result holds the availability information for a domain
+type Result struct {
+	Domain       string
+	Status       Status
+	Price        int64 // Registration price in micro-units of Currency (0 if unknown)
+	RenewalPrice int64 // Renewal price in micro-units of Currency (0 if unknown)
+	Period       int   // Registration period of the prices in years
+	Currency     string
+	Definitive   bool
+	Message      string
+	Source       string    // Name of the source that provided the result
+	Agreement    Agreement // Set if the result was cross-checked by multiple sources
+}
+
+// Available returns true if the domain can be registered
+func (r Result) Available() bool {
+	return r.Status == StatusAvailable || r.Status == StatusPremium
+}
+
 // Source is the interface for domain search sources
 type Source interface {
-	IsAvailable(string) (bool, error)
-}
-
-// Get returns a search source
-func Get(config interface{}, sourceType string) Source {
-	switch sourceType {
-	case DNSSource:
-		return NewDNS(config.(*DNSConfig)).(Source)
-	case GoDaddySource:
-		return NewGoDaddy(config.(*GoDaddyConfig)).(Source)
-	case NameCheapSource:
-		return NewNameCheap(config.(*NameCheapConfig)).(Source)
-	default:
-		panic("Invalid source: " + sourceType)
+	Name() string
+	IsAvailable(context.Context, string) (Result, error)
+}
+
+// Keyed is implemented by sources composed of other sources. Key identifies
+// the composition.
+type Keyed interface {
+	Key() string
+}
+
+// Key returns a key identifying the source. Sources that aren't composed of
+// other sources are identified by their name.
+func Key(s Source) string {
+	if k, ok := s.(Keyed); ok {
+		return k.Key()
 	}
+
+	return s.Name()
+}
+
+// BatchResult holds the result for a single domain of a batch check
+type BatchResult struct {
+	Result
+	Err error
+}
+
+// BatchSource is implemented by sources that can check multiple domains with
+// a single request
+type BatchSource interface {
+	Source
+	BatchSize() int
+	IsAvailableBatch(context.Context, []string) ([]BatchResult, error)
 }
@@ -0,0 +1,260 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	whoisPort         = "43"
+	whoisIANAServer   = "whois.iana.org"
+	whoisDefaultQuery = "%s"
+)
+
+// WHOISConfig holds the configuration for the WHOIS source
+type WHOISConfig struct {
+	Enabled    bool
+	IANAServer string // Server used to look up servers of TLDs that aren't configured
+	Servers    map[string]WHOISServer
+	LimitConfig
+}
+
+// WHOISServer holds the WHOIS server configuration for a TLD
+type WHOISServer struct {
+	Address  string   // host or host:port
+	Query    string   // Format of the query (i.e. "-T dn %s")
+	NotFound []string // Case insensitive patterns indicating that a domain isn't registered
+	Reserved []string // Case insensitive patterns indicating that a domain is reserved by the registry
+}
+
+var whoisLimits = LimitConfig{
+	RateLimit:  2,
+	RateBurst:  2,
+	MaxRetries: 2,
+	RetryDelay: 2000,
+	Timeout:    10,
+}
+
+// Patterns used for servers without configured patterns
+var whoisDefaultNotFound = []string{
+	"no match",
+	"not found",
+	"no data found",
+	"no entries found",
+	"no object found",
+	"nothing found",
+	"status: free",
+	"status: available",
+}
+
+var whoisDefaultServers = map[string]WHOISServer{
+	"com": {Address: "whois.verisign-grs.com", NotFound: []string{"no match for"}},
+	"net": {Address: "whois.verisign-grs.com", NotFound: []string{"no match for"}},
+	"org": {Address: "whois.publicinterestregistry.org", NotFound: []string{"domain not found"}},
+	"de":  {Address: "whois.denic.de", Query: "-T dn,ace %s", NotFound: []string{"status: free"}},
+	"uk":  {Address: "whois.nic.uk", NotFound: []string{"no match for"}},
+}
+
+// WHOIS handles checking availability of domain names via the WHOIS protocol
+type WHOIS struct {
+	config  *WHOISConfig
+	limiter *limiter
+	timeout time.Duration
+
+	mu      sync.Mutex
+	servers map[string]WHOISServer
+}
+
+func init() {
+	Register(WHOISSource, func() interface{} { return new(WHOISConfig) }, func(config interface{}) (Source, error) {
+		return NewWHOIS(config.(*WHOISConfig)), nil
+	})
+}
+
+// NewWHOIS returns a new WHOIS instance
+func NewWHOIS(config *WHOISConfig) Source {
+	w := new(WHOIS)
+
+	w.config = config
+	limits := config.LimitConfig.withDefaults(whoisLimits)
+	w.limiter = newLimiter(limits)
+	w.timeout = time.Duration(limits.Timeout) * time.Second
+
+	// Configured servers override the defaults
+	w.servers = make(map[string]WHOISServer)
+	for tld, server := range whoisDefaultServers {
+		w.servers[tld] = server
+	}
+	for tld, server := range config.Servers {
+		w.servers[strings.ToLower(tld)] = server
+	}
+
+	return w
+}
+
+// Name returns the name of the source
+func (w *WHOIS) Name() string {
+	return "whois"
+}
+
+// IsAvailable checks if a domain is available
+func (w *WHOIS) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result := Result{Domain: domain}
+
+	server, err := w.server(ctx, domain)
+	if err != nil {
+		return result, err
+	}
+
+	query := server.Query
+	if query == "" {
+		query = whoisDefaultQuery
+	}
+
+	var response string
+	err = w.limiter.do(ctx, func() error {
+		var err error
+		response, err = w.query(ctx, server.Address, fmt.Sprintf(query, domain))
+		return err
+	})
+	if err != nil {
+		return result, err
+	}
+
+	if strings.TrimSpace(response) == "" {
+		return result, &Error{Kind: ErrTransient, Message: "Empty WHOIS response"}
+	}
+
+	patterns := server.NotFound
+	if len(patterns) == 0 {
+		patterns = whoisDefaultNotFound
+	}
+
+	response = strings.ToLower(response)
+	switch {
+	case matchAny(response, server.Reserved):
+		result.Status = StatusReserved
+	case matchAny(response, patterns):
+		result.Status = StatusAvailable
+	default:
+		result.Status = StatusTaken
+	}
+
+	return result, nil
+}
+
+// server returns the WHOIS server for a domain. Servers for TLDs that aren't
+// configured are looked up via IANA.
+func (w *WHOIS) server(ctx context.Context, domain string) (WHOISServer, error) {
+	labels := strings.Split(strings.ToLower(domain), ".")
+
+	w.mu.Lock()
+	// Use the longest matching entry
+	for i := 1; i < len(labels); i++ {
+		if server, ok := w.servers[strings.Join(labels[i:], ".")]; ok {
+			w.mu.Unlock()
+			return server, nil
+		}
+	}
+	w.mu.Unlock()
+
+	// Don't block checks of other TLDs during the lookup
+	tld := labels[len(labels)-1]
+	address, err := w.lookupServer(ctx, tld)
+	if err != nil {
+		return WHOISServer{}, err
+	}
+
+	server := WHOISServer{Address: address}
+	w.mu.Lock()
+	w.servers[tld] = server
+	w.mu.Unlock()
+
+	return server, nil
+}
+
+// lookupServer looks up the WHOIS server for a TLD via IANA
+func (w *WHOIS) lookupServer(ctx context.Context, tld string) (string, error) {
+	ianaServer := w.config.IANAServer
+	if ianaServer == "" {
+		ianaServer = whoisIANAServer
+	}
+
+	response, err := w.query(ctx, ianaServer, tld)
+	if err != nil {
+		return "", err
+	}
+
+	scanner := bufio.NewScanner(strings.NewReader(response))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if strings.HasPrefix(strings.ToLower(line), "whois:") {
+			if address := strings.TrimSpace(line[len("whois:"):]); address != "" {
+				return address, nil
+			}
+		}
+	}
+
+	return "", &Error{Kind: ErrUnsupportedTLD, Message: "No WHOIS server for " + tld}
+}
+
+// query sends a query to a WHOIS server and returns the response. The
+// connection is closed when ctx is done.
+func (w *WHOIS) query(ctx context.Context, address, query string) (string, error) {
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, whoisPort)
+	}
+
+	dialer := &net.Dialer{Timeout: w.timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't connect to WHOIS server " + address}}
+	}
+	defer conn.Close()
+
+	conn.SetDeadline(time.Now().Add(w.timeout))
+
+	done := make(chan struct{})
+	defer close(done)
+	go func() {
+		select {
+		case <-ctx.Done():
+			conn.Close()
+		case <-done:
+		}
+	}()
+
+	if _, err := conn.Write([]byte(query + "\r\n")); err != nil {
+		return "", &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't send WHOIS query to " + address}}
+	}
+
+	response, err := ioutil.ReadAll(conn)
+	if ctx.Err() != nil {
+		return "", ctx.Err()
+	}
+	if err != nil {
+		return "", &retryableError{err: &Error{Kind: ErrTransient, Message: "Couldn't read WHOIS response from " + address}}
+	}
+
+	return string(response), nil
+}
+
+// matchAny returns true if the response contains any of the patterns
+func matchAny(response string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if strings.Contains(response, strings.ToLower(pattern)) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,85 @@
+package source
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"net"
+	"strings"
+	"testing"
+)
+
+// newWHOISStandIn starts a local fake WHOIS server answering queries with
+// respond and returns its address
+func newWHOISStandIn(t *testing.T, respond func(query string) string) string {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+
+	go func() {
+		for {
+			conn, err := l.Accept()
+			if err != nil {
+				return
+			}
+
+			go func() {
+				defer conn.Close()
+				query, _ := bufio.NewReader(conn).ReadString('\n')
+				fmt.Fprint(conn, respond(strings.TrimSpace(query)))
+			}()
+		}
+	}()
+
+	return l.Addr().String()
+}
+
+func TestWHOISIsAvailable(t *testing.T) {
+	registry := newWHOISStandIn(t, func(query string) string {
+		switch query {
+		case "-T dn free.test":
+			return "Status: free\n"
+		case "-T dn taken.test":
+			return "Domain: taken.test\nStatus: connect\n"
+		}
+		return ""
+	})
+	iana := newWHOISStandIn(t, func(query string) string {
+		if query == "example" {
+			return "domain: EXAMPLE\nwhois: " + registry + "\n"
+		}
+		return "% No whois server\n"
+	})
+
+	w := NewWHOIS(&WHOISConfig{
+		IANAServer: iana,
+		Servers: map[string]WHOISServer{
+			"test": {Address: registry, Query: "-T dn %s"},
+		},
+		LimitConfig: LimitConfig{RateLimit: -1, MaxRetries: -1},
+	})
+
+	tests := []struct {
+		domain  string
+		status  Status
+		wantErr bool
+	}{
+		{"free.test", StatusAvailable, false},
+		{"taken.test", StatusTaken, false},
+		{"unknown.example", StatusUnknown, true}, // Empty response
+		{"foo.unsupported", StatusUnknown, true},
+	}
+
+	for _, tt := range tests {
+		result, err := w.IsAvailable(context.Background(), tt.domain)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IsAvailable(%s) error = %v, want error %t", tt.domain, err, tt.wantErr)
+			continue
+		}
+		if result.Status != tt.status {
+			t.Errorf("IsAvailable(%s) = %s, want %s", tt.domain, result.Status, tt.status)
+		}
+	}
+}
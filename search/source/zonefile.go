@@ -0,0 +1,227 @@
+package source
+
+import (
+	"bufio"
+	"compress/gzip"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+)
+
+// ZoneFileConfig holds the configuration for the zone file source
+type ZoneFileConfig struct {
+	Enabled bool
+	Files   []string // Zone files (optionally gzipped)
+}
+
+// ZoneFile handles checking availability of domain names against local zone
+// files
+type ZoneFile struct {
+	config *ZoneFileConfig
+
+	loaded chan struct{}       // Closed once the zone files are loaded
+	zones  map[string][]string // Sorted delegated labels per TLD
+	err    error
+}
+
+func init() {
+	Register(ZoneFileSource, func() interface{} { return new(ZoneFileConfig) }, func(config interface{}) (Source, error) {
+		return NewZoneFile(config.(*ZoneFileConfig))
+	})
+}
+
+// NewZoneFile returns a new ZoneFile instance. The zone files are loaded in
+// the background.
+func NewZoneFile(config *ZoneFileConfig) (Source, error) {
+	if len(config.Files) == 0 {
+		return nil, errors.New("No zone files configured")
+	}
+
+	zf := new(ZoneFile)
+
+	zf.config = config
+	zf.loaded = make(chan struct{})
+
+	go func() {
+		zf.err = zf.load()
+		close(zf.loaded)
+	}()
+
+	return zf, nil
+}
+
+// Name returns the name of the source
+func (zf *ZoneFile) Name() string {
+	return "zonefile"
+}
+
+// IsAvailable checks if a domain is available
+func (zf *ZoneFile) IsAvailable(ctx context.Context, domain string) (Result, error) {
+	result := Result{Domain: domain}
+
+	select {
+	case <-zf.loaded:
+	case <-ctx.Done():
+		return result, ctx.Err()
+	}
+	if zf.err != nil {
+		return result, zf.err
+	}
+
+	domain = strings.TrimSuffix(strings.ToLower(domain), ".")
+	sep := strings.Index(domain, ".")
+	if sep == -1 {
+		return result, &Error{Kind: ErrInvalidDomain, Message: domain}
+	}
+
+	labels, ok := zf.zones[domain[sep+1:]]
+	if !ok {
+		return result, &Error{Kind: ErrUnsupportedTLD, Message: "No zone file for " + domain}
+	}
+
+	label := domain[:sep]
+	i := sort.SearchStrings(labels, label)
+	if i < len(labels) && labels[i] == label {
+		result.Status = StatusTaken
+		result.Definitive = true
+	} else {
+		result.Status = StatusAvailable
+	}
+
+	return result, nil
+}
+
+// load loads all configured zone files
+func (zf *ZoneFile) load() error {
+	zf.zones = make(map[string][]string)
+	for _, file := range zf.config.Files {
+		if err := loadZoneFile(file, zf.zones); err != nil {
+			return err
+		}
+	}
+
+	// Sorted slices use considerably less memory than maps
+	for tld, labels := range zf.zones {
+		sort.Strings(labels)
+		zf.zones[tld] = dedupe(labels)
+	}
+
+	return nil
+}
+
+// loadZoneFile adds the names delegated (NS records) in a zone file to zones
+func loadZoneFile(file string, zones map[string][]string) error {
+	fd, err := os.Open(file)
+	if err != nil {
+		return fmt.Errorf("Couldn't read zone file: %s", file)
+	}
+	defer fd.Close()
+
+	var r io.Reader = fd
+	if strings.HasSuffix(strings.ToLower(file), ".gz") {
+		gz, err := gzip.NewReader(fd)
+		if err != nil {
+			return fmt.Errorf("Couldn't decompress zone file: %s", file)
+		}
+		defer gz.Close()
+		r = gz
+	}
+
+	origin := ""
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if i := strings.IndexByte(line, ';'); i != -1 {
+			line = line[:i]
+		}
+
+		fields := strings.Fields(strings.ToLower(line))
+		if len(fields) < 2 {
+			continue
+		}
+
+		if fields[0] == "$origin" {
+			origin = strings.TrimSuffix(fields[1], ".")
+			continue
+		}
+
+		// Owner names are only relevant for delegations
+		if !isNSRecord(fields) || line[0] == ' ' || line[0] == '\t' {
+			continue
+		}
+
+		name := fields[0]
+		if name == "@" {
+			name = origin
+		} else if strings.HasSuffix(name, ".") {
+			name = strings.TrimSuffix(name, ".")
+		} else if origin != "" {
+			name = name + "." + origin
+		}
+
+		sep := strings.Index(name, ".")
+		if sep == -1 {
+			continue
+		}
+
+		// Delegations usually have multiple consecutive NS records. The
+		// labels are copied so they don't keep the whole line in memory.
+		tld, label := name[sep+1:], name[:sep]
+		labels, ok := zones[tld]
+		if !ok {
+			tld = strings.Clone(tld)
+		}
+		if len(labels) == 0 || labels[len(labels)-1] != label {
+			zones[tld] = append(labels, strings.Clone(label))
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("Couldn't read zone file: %s", file)
+	}
+
+	return nil
+}
+
+// isNSRecord returns true if the fields of a record line describe an NS
+// record. The TTL and class fields are optional.
+func isNSRecord(fields []string) bool {
+	for _, field := range fields[1:] {
+		switch field {
+		case "ns":
+			return true
+		case "in", "ch", "hs":
+			continue
+		}
+
+		// TTL
+		if field[0] >= '0' && field[0] <= '9' {
+			continue
+		}
+
+		return false
+	}
+
+	return false
+}
+
+// dedupe removes duplicates from a sorted slice
+func dedupe(labels []string) []string {
+	if len(labels) == 0 {
+		return labels
+	}
+
+	unique := labels[:1]
+	for _, label := range labels[1:] {
+		if label != unique[len(unique)-1] {
+			unique = append(unique, label)
+		}
+	}
+
+	return unique[:len(unique):len(unique)]
+}
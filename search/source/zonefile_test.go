@@ -0,0 +1,94 @@
+package source
+
+import (
+	"compress/gzip"
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+const testZone = `$ORIGIN com.
+$TTL 86400
+; Delegations
+@ IN SOA a.gtld-servers.net. nstld.verisign-grs.com. 1 1800 900 604800 86400
+@ IN NS a.gtld-servers.net.
+foo NS ns1.foo.net.
+foo NS ns2.foo.net.
+BAR 172800 IN NS ns1.bar.org. ; comment
+baz.com. IN 172800 NS ns1.baz.org.
+	IN NS ns2.baz.org.
+qux IN A 192.0.2.1
+ns1.foo A 192.0.2.2
+$ORIGIN co.uk.
+example NS ns1.example.net.
+`
+
+func TestLoadZoneFile(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "com.zone")
+	if err := ioutil.WriteFile(file, []byte(testZone), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	zones := make(map[string][]string)
+	if err := loadZoneFile(file, zones); err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string][]string{
+		"com":   {"foo", "bar", "baz"},
+		"co.uk": {"example"},
+	}
+	if !reflect.DeepEqual(zones, want) {
+		t.Errorf("loadZoneFile() = %v, want %v", zones, want)
+	}
+}
+
+func TestLoadZoneFileGzip(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "com.zone.gz")
+	fd, err := os.Create(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	gz := gzip.NewWriter(fd)
+	gz.Write([]byte(testZone))
+	gz.Close()
+	fd.Close()
+
+	zf, err := NewZoneFile(&ZoneFileConfig{Files: []string{file}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		domain  string
+		status  Status
+		wantErr bool
+	}{
+		{"foo.com", StatusTaken, false},
+		{"BAR.com", StatusTaken, false},
+		{"qux.com", StatusAvailable, false},
+		{"example.co.uk", StatusTaken, false},
+		{"foo.net", StatusUnknown, true},
+		{"com", StatusUnknown, true},
+	}
+
+	for _, tt := range tests {
+		result, err := zf.IsAvailable(context.Background(), tt.domain)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("IsAvailable(%s) error = %v, want error %t", tt.domain, err, tt.wantErr)
+			continue
+		}
+		if result.Status != tt.status {
+			t.Errorf("IsAvailable(%s) = %s, want %s", tt.domain, result.Status, tt.status)
+		}
+	}
+}
+
+func TestLoadZoneFileMissing(t *testing.T) {
+	if err := loadZoneFile(filepath.Join(t.TempDir(), "missing.zone"), make(map[string][]string)); err == nil {
+		t.Error("loadZoneFile() error = nil, want error")
+	}
+}